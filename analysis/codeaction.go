@@ -0,0 +1,50 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/conventional"
+	"cc-lsp/lsp"
+)
+
+func (s *State) TextDocumentCodeAction(id int, uri string, context lsp.CodeActionContext) lsp.TextDocumentCodeActionResponse {
+	actions := []lsp.CodeAction{}
+	commit := conventional.Parse(s.Documents[uri])
+
+	for _, diagnostic := range context.Diagnostics {
+		if diagnostic.Code == config.GoAPIBreaking && commit.Conventional && !commit.Breaking {
+			actions = append(actions, lsp.CodeAction{
+				Title:       "Mark as breaking change",
+				Kind:        lsp.QuickFix,
+				Diagnostics: []lsp.Diagnostic{diagnostic},
+				IsPreferred: true,
+				Edit: &lsp.WorkspaceEdit{
+					Changes: map[string][]lsp.TextEdit{
+						uri: {breakingMarkerEdit(*commit.Header)},
+					},
+				},
+			})
+		}
+	}
+
+	return lsp.TextDocumentCodeActionResponse{
+		Response: lsp.Response{
+			RPC: "2.0",
+			ID:  &id,
+		},
+		Result: actions,
+	}
+}
+
+// breakingMarkerEdit inserts the ! in front of the colon of the header
+func breakingMarkerEdit(header conventional.Header) lsp.TextEdit {
+	position := header.Type.End
+	if header.Scope.End.Character > 0 {
+		// behind the closing parenthesis of the scope
+		position = header.Scope.End
+		position.Character++
+	}
+	return lsp.TextEdit{
+		Range:   lsp.Range{Start: position, End: position},
+		NewText: "!",
+	}
+}
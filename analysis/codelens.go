@@ -0,0 +1,119 @@
+package analysis
+
+import (
+	"cc-lsp/conventional"
+	"cc-lsp/git"
+	"cc-lsp/lsp"
+	"cc-lsp/release"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// previewFile is the location of the changelog preview inside the git directory
+const previewFile = "cc-lsp/CHANGELOG-preview.md"
+
+// CodeLens shows on the header how the message ends up in the changelog and
+// how the scope was used so far
+func (s *State) CodeLens(id int, uri string) lsp.CodeLensResponse {
+	lenses := []lsp.CodeLens{}
+	document := s.Documents[uri]
+	commit := conventional.Parse(document)
+	if header := commit.Header; header != nil {
+		line, _ := headerLine(document, header)
+		headerRange := LineRange(header.Line, 0, len(line))
+		lenses = append(lenses, lsp.CodeLens{
+			Range: headerRange,
+			Command: &lsp.Command{
+				Title:     changelogLensTitle(document),
+				Command:   lsp.PreviewChangelogCommand,
+				Arguments: []any{uri},
+			},
+		})
+		if title, ok := s.scopeLensTitle(uri, commit); ok {
+			lenses = append(lenses, lsp.CodeLens{
+				Range:   headerRange,
+				Command: &lsp.Command{Title: title},
+			})
+		}
+	}
+
+	return lsp.CodeLensResponse{
+		Response: lsp.Response{
+			RPC: "2.0",
+			ID:  &id,
+		},
+		Result: lenses,
+	}
+}
+
+// changelogLensTitle renders the entry of the message in the release notes,
+// e.g. "Changelog: ### Features — **api:** add pagination"
+func changelogLensTitle(document string) string {
+	title, line, ok := release.NotesEntry(document)
+	if !ok {
+		return "Changelog: not listed in the release notes"
+	}
+	return "Changelog: ### " + title + " — " + strings.TrimPrefix(line, "* ")
+}
+
+// scopeLensTitle summarizes the history of the scope, e.g.
+// "api: 128 commits, last by alice"
+func (s *State) scopeLensTitle(uri string, commit conventional.Commit) (string, bool) {
+	if !commit.Conventional || commit.Scope == "" {
+		return "", false
+	}
+	repo, err := repoForURI(uri)
+	if err != nil {
+		return "", false
+	}
+	history, err := s.history(repo)
+	if err != nil {
+		return "", false
+	}
+	stat, ok := history.Scopes[commit.Scope]
+	if !ok {
+		return commit.Scope + ": no commits yet", true
+	}
+	commits := "commits"
+	if stat.Count == 1 {
+		commits = "commit"
+	}
+	return fmt.Sprintf("%s: %d %s, last by %s", commit.Scope, stat.Count, commits, stat.Author), true
+}
+
+// PreviewChangelog renders the release notes of the next release with the
+// message of the document as if it was committed, the notes are written to
+// a file in the git directory and its URI is returned
+func (s *State) PreviewChangelog(uri string) (string, error) {
+	repo, err := repoForURI(uri)
+	if err != nil {
+		return "", err
+	}
+	cfg, err := s.config(repo)
+	if err != nil {
+		return "", err
+	}
+	document := s.Documents[uri]
+	options := releaseOptions(cfg, conventional.Parse(document))
+	plan, err := release.PreviewVersion(repo, options, git.Commit{Message: document, Files: s.staged[uri]})
+	if err != nil {
+		return "", err
+	}
+
+	gitDir, err := repo.GitDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(gitDir, previewFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return "", err
+	}
+	notes := release.RenderNotes(plan.Next, time.Now(), plan.Commits)
+	if err := os.WriteFile(path, []byte(notes), 0666); err != nil {
+		return "", err
+	}
+	return pathToURI(path), nil
+}
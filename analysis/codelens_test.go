@@ -0,0 +1,64 @@
+package analysis
+
+import (
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCodeLens(t *testing.T) {
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"-c", "user.name=alice", "-c", "user.email=alice@example.com", "commit", "--quiet", "--allow-empty", "--message", "feat(api): add the list endpoint"},
+		{"tag", "v1.4.2"},
+		{"-c", "user.name=bob", "-c", "user.email=bob@example.com", "commit", "--quiet", "--allow-empty", "--message", "fix(api): off by one"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+
+	uri := "file://" + filepath.ToSlash(dir) + "/.git/COMMIT_EDITMSG"
+	state := NewState()
+	cases := []struct {
+		document string
+		titles   []string
+	}{
+		{"feat(api): add pagination\n# comment", []string{"Changelog: ### Features — **api:** add pagination", "api: 2 commits, last by bob"}},
+		{"docs(cli): explain flags", []string{"Changelog: not listed in the release notes", "cli: no commits yet"}},
+		{"refactor!: drop v1", []string{"Changelog: ### ⚠ BREAKING CHANGES — drop v1"}},
+		{"", []string{}},
+	}
+	for idx, tc := range cases {
+		state.OpenDocument(uri, tc.document)
+		titles := []string{}
+		for _, lens := range state.CodeLens(1, uri).Result {
+			titles = append(titles, lens.Command.Title)
+		}
+		if strings.Join(titles, "\n") != strings.Join(tc.titles, "\n") {
+			t.Fatalf("Test case %d failed. Got %q - Exp %q", idx, titles, tc.titles)
+		}
+	}
+
+	state.OpenDocument(uri, "feat(api): add pagination")
+	preview, err := state.PreviewChangelog(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _ := url.Parse(preview)
+	notes, err := os.ReadFile(parsed.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"## 1.5.0 (", "* **api:** add pagination\n", "* **api:** off by one ("} {
+		if !strings.Contains(string(notes), expected) {
+			t.Fatalf("the preview should contain %q, got %s", expected, notes)
+		}
+	}
+}
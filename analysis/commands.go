@@ -0,0 +1,249 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/git"
+	"cc-lsp/lsp"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// CommandResult is what a command produces, the server hands it to the client
+type CommandResult struct {
+	// Edit is applied with a workspace/applyEdit request
+	Edit *lsp.WorkspaceEdit
+	// Label describes the edit in the undo history of the client
+	Label string
+	// Confirm asks the user before the edit is applied, it is set if the
+	// edit replaces a message
+	Confirm string
+	// Show is the URI of a document to open
+	Show string
+	// Message is shown to the user
+	Message string
+	// Diagnostics are published again, e.g. after the config changed
+	Diagnostics map[string][]lsp.Diagnostic
+}
+
+// ExecuteCommand runs one of the commands of the server on the document
+func (s *State) ExecuteCommand(command, uri string) (CommandResult, error) {
+	switch command {
+	case lsp.GenerateFromDiffCommand:
+		return s.generateFromDiff(uri)
+	case lsp.InsertTemplateCommand:
+		return s.insertTemplate(uri)
+	case lsp.PreviewChangelogCommand:
+		preview, err := s.PreviewChangelog(uri)
+		return CommandResult{Show: preview}, err
+	case lsp.ReloadConfigCommand:
+		return CommandResult{Message: "Reloaded the configuration", Diagnostics: s.Reload()}, nil
+	case lsp.RestoreDraftCommand:
+		return s.restoreDraft(uri)
+	}
+	return CommandResult{}, fmt.Errorf("unknown command %s", command)
+}
+
+// messageEdit replaces the message of the document, the comments below it
+// stay as they are
+func (s *State) messageEdit(uri, label, message string) CommandResult {
+	document := s.Documents[uri]
+	result := CommandResult{
+		Edit: &lsp.WorkspaceEdit{
+			Changes: map[string][]lsp.TextEdit{
+				uri: lineEdits(document, withMessage(document, message)),
+			},
+		},
+		Label: label,
+	}
+	if messageOf(document) != "" {
+		result.Confirm = label + " and replace the current message?"
+	}
+	return result
+}
+
+// messageEnd returns the line of the first comment, the message is above it
+func messageEnd(lines []string) int {
+	end := 0
+	for end < len(lines) && !strings.HasPrefix(lines[end], "#") {
+		end++
+	}
+	return end
+}
+
+// messageOf returns the message of the document without the comments
+func messageOf(document string) string {
+	lines := strings.Split(document, "\n")
+	return strings.TrimSpace(strings.Join(lines[:messageEnd(lines)], "\n"))
+}
+
+// withMessage returns the document with the message replaced, a blank line
+// separates it from the comments
+func withMessage(document, message string) string {
+	lines := strings.Split(document, "\n")
+	end := messageEnd(lines)
+	if end == len(lines) {
+		if strings.HasSuffix(document, "\n") {
+			return message + "\n"
+		}
+		return message
+	}
+	return message + "\n\n" + strings.Join(lines[end:], "\n")
+}
+
+// statusVerbs describe the change of a staged file
+var statusVerbs = map[byte]string{
+	'A': "add",
+	'C': "copy",
+	'D': "remove",
+	'R': "rename",
+}
+
+func statusVerb(status byte) string {
+	if verb, ok := statusVerbs[status]; ok {
+		return verb
+	}
+	return "update"
+}
+
+// generateFromDiff writes a message from the staged files: the type and the
+// scope they suggest and a line for every file. Without a suggested type it
+// is feat if files were added and fix otherwise, a starting point to edit.
+func (s *State) generateFromDiff(uri string) (CommandResult, error) {
+	repo, err := repoForURI(uri)
+	if err != nil {
+		return CommandResult{}, err
+	}
+	files, err := repo.StagedStatus()
+	if err != nil {
+		return CommandResult{}, err
+	}
+	if len(files) == 0 {
+		return CommandResult{}, errors.New("nothing is staged")
+	}
+	cfg, err := s.config(repo)
+	if err != nil {
+		return CommandResult{}, err
+	}
+	return s.messageEdit(uri, "Generate the message from the staged changes", generateMessage(files, cfg)), nil
+}
+
+func generateMessage(files []git.FileStatus, cfg config.Config) string {
+	paths := []string{}
+	verbs := map[string]bool{}
+	body := []string{}
+	for _, file := range files {
+		paths = append(paths, file.Path)
+		verbs[statusVerb(file.Status)] = true
+		body = append(body, "- "+statusVerb(file.Status)+" "+file.Path)
+	}
+
+	suggestion := suggestFromStaged(paths, cfg)
+	typ := suggestion.typ
+	if typ == "" {
+		typ = "fix"
+		if verbs["add"] {
+			typ = "feat"
+		}
+	}
+	header := typ
+	if suggestion.scope != "" {
+		header += "(" + suggestion.scope + ")"
+	}
+
+	verb := "update"
+	if len(verbs) == 1 {
+		verb = statusVerb(files[0].Status)
+	}
+	if len(files) == 1 {
+		return header + ": " + verb + " " + path.Base(files[0].Path)
+	}
+	return header + ": " + fmt.Sprintf("%s %d files", verb, len(files)) + "\n\n" + strings.Join(body, "\n")
+}
+
+// messageTemplate is inserted if the repository has no commit.template
+const messageTemplate = "<type>(<scope>): <description>\n\n<what changed and why>\n\nRefs: <issue>"
+
+// insertTemplate fills an empty message with the commit.template of the
+// repository or a template of a conventional commit
+func (s *State) insertTemplate(uri string) (CommandResult, error) {
+	if messageOf(s.Documents[uri]) != "" {
+		return CommandResult{}, errors.New("the message is not empty")
+	}
+	template := messageTemplate
+	if repo, err := repoForURI(uri); err == nil {
+		if file := repo.Config("commit.template"); file != "" {
+			if rest, ok := strings.CutPrefix(file, "~/"); ok {
+				home, _ := os.UserHomeDir()
+				file = filepath.Join(home, rest)
+			} else if !filepath.IsAbs(file) {
+				file = filepath.Join(repo.Dir, file)
+			}
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return CommandResult{}, err
+			}
+			template = strings.TrimSpace(string(content))
+		}
+	}
+	return s.messageEdit(uri, "Insert the commit template", template), nil
+}
+
+// draftFile is the location of the last message written inside the git directory
+const draftFile = "cc-lsp/draft"
+
+// loadDraft reads the message written last time, a draft that was committed
+// is no draft anymore
+func loadDraft(repo git.Repo) string {
+	gitDir, err := repo.GitDir()
+	if err != nil {
+		return ""
+	}
+	draft, err := os.ReadFile(filepath.Join(gitDir, draftFile))
+	if err != nil {
+		return ""
+	}
+	if last, err := repo.LastMessage(); err == nil && last == strings.TrimSpace(string(draft)) {
+		return ""
+	}
+	return strings.TrimSpace(string(draft))
+}
+
+// saveDraft keeps the message of the document so it can be restored after
+// an aborted or failed commit
+func saveDraft(repo git.Repo, document string) error {
+	message := messageOf(document)
+	if message == "" {
+		return nil
+	}
+	gitDir, err := repo.GitDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(gitDir, draftFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(message+"\n"), 0666)
+}
+
+// restoreDraft replaces the message with the one written before the document
+// was opened
+func (s *State) restoreDraft(uri string) (CommandResult, error) {
+	draft := s.drafts[uri]
+	if draft == "" {
+		return CommandResult{}, errors.New("there is no draft to restore")
+	}
+	return s.messageEdit(uri, "Restore the draft", draft), nil
+}
+
+// Reload forgets the cached scopes, reads the staged changes again and
+// returns the diagnostics of every document
+func (s *State) Reload() map[string][]lsp.Diagnostic {
+	clear(s.histories)
+	clear(s.layouts)
+	return s.lintAll()
+}
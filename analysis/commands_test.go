@@ -0,0 +1,116 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/git"
+	"cc-lsp/lsp"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateMessage(t *testing.T) {
+	cases := []struct {
+		files    []git.FileStatus
+		expected string
+	}{
+		{[]git.FileStatus{{Status: 'M', Path: "analysis/state.go"}}, "fix(analysis): update state.go"},
+		{[]git.FileStatus{{Status: 'A', Path: "docs/usage.md"}}, "docs(docs): add usage.md"},
+		{[]git.FileStatus{{Status: 'A', Path: "api/list.go"}, {Status: 'M', Path: "api/routes.go"}}, "feat(api): update 2 files\n\n- add api/list.go\n- update api/routes.go"},
+		{[]git.FileStatus{{Status: 'D', Path: "a.go"}, {Status: 'D', Path: "b.go"}}, "fix: remove 2 files\n\n- remove a.go\n- remove b.go"},
+	}
+
+	for idx, tc := range cases {
+		if actual := generateMessage(tc.files, config.Config{}); actual != tc.expected {
+			t.Fatalf("Test case %d failed. Got %q - Exp %q", idx, actual, tc.expected)
+		}
+	}
+}
+
+func TestWithMessage(t *testing.T) {
+	cases := []struct {
+		document string
+		expected string
+	}{
+		{"", "feat: x"},
+		{"\n", "feat: x\n"},
+		{"old\n# comment\n", "feat: x\n\n# comment\n"},
+		{"\n# comment", "feat: x\n\n# comment"},
+	}
+
+	for idx, tc := range cases {
+		if actual := withMessage(tc.document, "feat: x"); actual != tc.expected {
+			t.Fatalf("Test case %d failed. Got %q - Exp %q", idx, actual, tc.expected)
+		}
+	}
+}
+
+func TestCommands(t *testing.T) {
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--allow-empty", "--message", "feat: init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+	uri := "file://" + filepath.ToSlash(dir) + "/.git/COMMIT_EDITMSG"
+	apply := func(state *State, result CommandResult) string {
+		if result.Edit == nil {
+			t.Fatalf("the command should edit the document, got %+v", result)
+		}
+		return applyEdits(state.Documents[uri], result.Edit.Changes[uri])
+	}
+
+	// the message of an aborted commit is the draft of the next one
+	state := NewState()
+	state.OpenDocument(uri, "\n# comment")
+	if _, err := state.ExecuteCommand(lsp.RestoreDraftCommand, uri); err == nil {
+		t.Fatal("there should be no draft yet")
+	}
+	state.UpdateDocument(uri, "fix: keep the draft\n# comment")
+	if _, err := os.Stat(filepath.Join(dir, ".git", draftFile)); err == nil {
+		t.Fatal("the draft should only be saved once the document is saved or closed")
+	}
+	if err := state.CloseDocument(uri); err != nil {
+		t.Fatal(err)
+	}
+
+	state = NewState()
+	state.OpenDocument(uri, "\n# comment")
+	result, err := state.ExecuteCommand(lsp.RestoreDraftCommand, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := apply(&state, result); actual != "fix: keep the draft\n\n# comment" || result.Confirm != "" {
+		t.Fatalf("Got %q %q", actual, result.Confirm)
+	}
+	state.UpdateDocument(uri, "fix: typed\n\n# comment")
+	if result, _ := state.ExecuteCommand(lsp.RestoreDraftCommand, uri); result.Confirm == "" {
+		t.Fatal("replacing a message should be confirmed")
+	}
+	state.UpdateDocument(uri, "\n# comment")
+
+	result, err = state.ExecuteCommand(lsp.InsertTemplateCommand, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := apply(&state, result); actual != messageTemplate+"\n\n# comment" {
+		t.Fatalf("Got %q", actual)
+	}
+	state.UpdateDocument(uri, "feat: x")
+	if _, err := state.ExecuteCommand(lsp.InsertTemplateCommand, uri); err == nil {
+		t.Fatal("the template should not replace a message")
+	}
+
+	if _, err := state.ExecuteCommand(lsp.GenerateFromDiffCommand, uri); err == nil {
+		t.Fatal("there is nothing staged to generate a message from")
+	}
+	if result, err := state.ExecuteCommand(lsp.ReloadConfigCommand, uri); err != nil || len(result.Diagnostics) != 1 {
+		t.Fatalf("the diagnostics of the document should be published again, got %+v %v", result, err)
+	}
+}
@@ -0,0 +1,281 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/conventional"
+	"cc-lsp/lsp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// completionContext is the part of the commit message the cursor is in
+type completionContext int
+
+const (
+	noCompletion completionContext = iota
+	typeCompletion
+	scopeCompletion
+	trailerCompletion
+)
+
+// tokenPrefixRegexp matches the start of a footer token that is being typed
+var tokenPrefixRegexp = regexp.MustCompile(`^[A-Za-z][\w-]*( [A-Z]*)?$|^$`)
+
+// getCompletionContext finds out what can be completed at the position
+func getCompletionContext(document string, position lsp.Position) completionContext {
+	lines := strings.Split(document, "\n")
+	if position.Line < 0 || position.Line >= len(lines) {
+		return noCompletion
+	}
+	for _, line := range lines[:position.Line+1] {
+		if strings.TrimRight(line, "\r") == conventional.ScissorsLine {
+			return noCompletion
+		}
+	}
+	line := strings.TrimRight(lines[position.Line], "\r")
+	if strings.HasPrefix(line, "#") {
+		return noCompletion
+	}
+	before := line[:min(max(position.Character, 0), len(line))]
+
+	commit := conventional.Parse(document)
+	if commit.Header == nil || position.Line <= commit.Header.Line {
+		// no header yet or the cursor is on it
+		if strings.ContainsAny(before, ":! ") {
+			return noCompletion
+		}
+		if strings.Count(before, "(") > strings.Count(before, ")") {
+			return scopeCompletion
+		}
+		if !strings.ContainsAny(before, "()") {
+			return typeCompletion
+		}
+		return noCompletion
+	}
+
+	if isTrailerLine(lines, commit, position.Line) && tokenPrefixRegexp.MatchString(before) {
+		return trailerCompletion
+	}
+	return noCompletion
+}
+
+// completionRange returns the range of the partially typed word at the
+// position that a completion replaces, lineEnd is true if nothing follows it
+func completionRange(document string, position lsp.Position, context completionContext) (lsp.Range, bool) {
+	lines := strings.Split(document, "\n")
+	if context == noCompletion {
+		return lsp.Range{Start: position, End: position}, false
+	}
+	line := strings.TrimRight(lines[position.Line], "\r")
+	character := min(max(position.Character, 0), len(line))
+
+	isWordChar := isTypeChar
+	switch context {
+	case scopeCompletion:
+		isWordChar = isScopeChar
+	case trailerCompletion:
+		isWordChar = isTokenChar
+	}
+
+	start, end := character, character
+	for start > 0 && isWordChar(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isWordChar(line[end]) {
+		end++
+	}
+	// the trailer completions bring their own colon
+	if context == trailerCompletion && end < len(line) && line[end] == ':' {
+		end++
+		if end < len(line) && line[end] == ' ' {
+			end++
+		}
+	}
+	return LineRange(position.Line, start, end), strings.TrimSpace(line[end:]) == ""
+}
+
+func isTypeChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isScopeChar(c byte) bool {
+	return c != '(' && c != ')' && c != ':' && c != '!' && c != ' '
+}
+
+func isTokenChar(c byte) bool {
+	// BREAKING CHANGE is the only token with a space
+	return isTypeChar(c) || '0' <= c && c <= '9' || c == '-' || c == '_' || c == ' '
+}
+
+// setTextEdits makes every item replace the range, so the partially typed
+// word is replaced the same way in every client
+func setTextEdits(items []lsp.CompletionItem, wordRange lsp.Range) {
+	for idx := range items {
+		text := items[idx].InsertText
+		if text == "" {
+			text = items[idx].Label
+		}
+		items[idx].TextEdit = &lsp.TextEdit{Range: wordRange, NewText: text}
+	}
+}
+
+// isTrailerLine reports whether a footer can start at the line: it is in the
+// last paragraph of the message and the paragraph only holds footers so far
+func isTrailerLine(lines []string, commit conventional.Commit, number int) bool {
+	// the line right after the header separates it from the body
+	if number == commit.Header.Line+1 {
+		return false
+	}
+
+	// everything below the line has to be empty, comments or footers
+	footerLines := map[int]bool{}
+	for _, footer := range commit.Footers {
+		footerLines[footer.Line] = true
+	}
+
+	// the line itself must not hold body text
+	current := strings.TrimRight(lines[number], "\r")
+	if !footerLines[number] && !tokenPrefixRegexp.MatchString(strings.TrimSpace(current)) {
+		return false
+	}
+	for idx := number + 1; idx < len(lines); idx++ {
+		line := strings.TrimRight(lines[idx], "\r")
+		if line == conventional.ScissorsLine {
+			break
+		}
+		if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "#") && !footerLines[idx] {
+			return false
+		}
+	}
+
+	// the line above has to end the body or be a footer itself
+	for idx := number - 1; idx > commit.Header.Line; idx-- {
+		line := lines[idx]
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		return strings.TrimSpace(line) == "" || footerLines[idx]
+	}
+	return false
+}
+
+// stagedDetail is added to the detail of completions the staged changes suggest
+const stagedDetail = " (suggested by the staged changes)"
+
+// suggestion returns the type and scope the staged changes of the document
+// point to
+func (s *State) suggestion(uri string, cfg config.Config) stagedSuggestion {
+	return suggestFromStaged(s.staged[uri], cfg)
+}
+
+// typeCompletions offers the types, the one the staged changes suggest first
+func (s *State) typeCompletions(uri string, snippets bool) []lsp.CompletionItem {
+	cfg := config.Config{}
+	if repo, err := repoForURI(uri); err == nil {
+		cfg, _ = s.config(repo)
+	}
+	suggested := s.suggestion(uri, cfg).typ
+
+	items := lsp.GetCompletions()
+	for idx := range items {
+		items[idx].SortText = fmt.Sprintf("1%02d", idx)
+		items[idx].Data = completionData{Kind: typeItem, Name: items[idx].Label}.encode()
+		if items[idx].Label == suggested {
+			items[idx].SortText = "0"
+			items[idx].Detail += stagedDetail
+			items[idx].Preselect = true
+		}
+	}
+	if snippets && s.Capabilities.TextDocument.Completion.CompletionItem.SnippetSupport {
+		items = append(items, headerSnippets()...)
+	}
+	return items
+}
+
+// headerSnippets offers whole headers with tab stops for every type and a
+// template for a breaking change with its footer
+func headerSnippets() []lsp.CompletionItem {
+	const snippet = 15
+	items := []lsp.CompletionItem{}
+	for idx, typ := range lsp.Prefixes {
+		items = append(items, lsp.CompletionItem{
+			Label:            typ + "(scope): description",
+			Kind:             snippet,
+			Detail:           "header template",
+			Data:             completionData{Kind: typeItem, Name: typ}.encode(),
+			SortText:         fmt.Sprintf("2%02d", idx),
+			FilterText:       typ,
+			InsertText:       typ + "(${1:scope}): ${2:description}",
+			InsertTextFormat: lsp.SnippetFormat,
+		})
+	}
+	items = append(items, lsp.CompletionItem{
+		Label:            "type(scope)!: description with BREAKING CHANGE footer",
+		Kind:             snippet,
+		Detail:           "breaking change template",
+		SortText:         "3",
+		FilterText:       strings.Join(lsp.Prefixes, " "),
+		InsertText:       "${1|" + strings.Join(lsp.Prefixes, ",") + "|}(${2:scope})!: ${3:description}\n\nBREAKING CHANGE: ${4:what breaks and how to migrate}",
+		InsertTextFormat: lsp.SnippetFormat,
+		Data:             completionData{Kind: breakingItem}.encode(),
+	})
+	return items
+}
+
+// scopeCompletions offers the known scopes of the repository that owns the
+// document. The scope the staged changes suggest comes first, then the scopes
+// of the history ranked by frequency and recency.
+func (s *State) scopeCompletions(uri string) []lsp.CompletionItem {
+	const module = 9
+	items := []lsp.CompletionItem{}
+	repo, err := repoForURI(uri)
+	if err != nil {
+		return items
+	}
+	cfg, err := s.config(repo)
+	if err != nil {
+		return items
+	}
+
+	known := s.knownScopes(repo, cfg)
+	if suggested := s.suggestion(uri, cfg).scope; suggested != "" {
+		scope := knownScope{name: suggested, detail: "scope"}
+		for idx, k := range known {
+			if k.name == suggested {
+				scope = k
+				known = slices.Delete(known, idx, idx+1)
+				break
+			}
+		}
+		scope.detail += stagedDetail
+		known = append([]knownScope{scope}, known...)
+	}
+
+	for _, scope := range known {
+		items = append(items, lsp.CompletionItem{
+			Label:    scope.name,
+			Kind:     module,
+			Detail:   scope.detail,
+			SortText: fmt.Sprintf("%05d", len(items)),
+			Data:     completionData{Kind: scopeItem, Name: scope.name, URI: uri}.encode(),
+		})
+	}
+	return items
+}
+
+func trailerCompletions() []lsp.CompletionItem {
+	const property = 10
+	items := []lsp.CompletionItem{}
+	for _, trailer := range lsp.Trailers {
+		items = append(items, lsp.CompletionItem{
+			Label:      trailer.Key + ":",
+			InsertText: trailer.Key + ": ",
+			Kind:       property,
+			Detail:     "footer",
+			Data:       completionData{Kind: trailerItem, Name: trailer.Key}.encode(),
+		})
+	}
+	return items
+}
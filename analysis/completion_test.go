@@ -0,0 +1,130 @@
+package analysis
+
+import (
+	"cc-lsp/lsp"
+	"testing"
+)
+
+func TestGetCompletionContext(t *testing.T) {
+	message := "feat(api): add pagination\n\nThe list endpoints return pages now.\n\nRefs: #12\n\n# Please enter the commit message"
+	cases := []struct {
+		document string
+		position lsp.Position
+		expected completionContext
+	}{
+		{"", lsp.Position{Line: 0, Character: 0}, typeCompletion},
+		{"fe", lsp.Position{Line: 0, Character: 2}, typeCompletion},
+		{"\n# comment\nfe", lsp.Position{Line: 0, Character: 0}, typeCompletion},
+		{"\n# comment\nfe", lsp.Position{Line: 1, Character: 3}, noCompletion},
+		{"feat(", lsp.Position{Line: 0, Character: 5}, scopeCompletion},
+		{"feat(ap): x", lsp.Position{Line: 0, Character: 7}, scopeCompletion},
+		{"feat(api): x", lsp.Position{Line: 0, Character: 9}, noCompletion},
+		{"feat(api): x", lsp.Position{Line: 0, Character: 11}, noCompletion},
+		{message, lsp.Position{Line: 0, Character: 2}, typeCompletion},
+		{message, lsp.Position{Line: 1, Character: 0}, noCompletion},
+		{message, lsp.Position{Line: 2, Character: 0}, noCompletion},
+		{message, lsp.Position{Line: 2, Character: 10}, noCompletion},
+		{message, lsp.Position{Line: 3, Character: 0}, noCompletion},
+		{message, lsp.Position{Line: 4, Character: 2}, trailerCompletion},
+		{message, lsp.Position{Line: 4, Character: 8}, noCompletion},
+		{message, lsp.Position{Line: 5, Character: 0}, trailerCompletion},
+		{message, lsp.Position{Line: 6, Character: 0}, noCompletion},
+		{"fix: x\n\nbody\n\nBREAKING C", lsp.Position{Line: 4, Character: 10}, trailerCompletion},
+		{"fix: x\n\nbody\nmore body", lsp.Position{Line: 3, Character: 0}, noCompletion},
+		{"fix: x\n\n\nbody", lsp.Position{Line: 2, Character: 0}, noCompletion},
+		{"fix: x\n# ------------------------ >8 ------------------------\n", lsp.Position{Line: 2, Character: 0}, noCompletion},
+	}
+
+	for idx, tc := range cases {
+		if actual := getCompletionContext(tc.document, tc.position); actual != tc.expected {
+			t.Fatalf("Test case %d failed. Got %d - Exp %d", idx, actual, tc.expected)
+		}
+	}
+}
+
+func TestHeaderSnippets(t *testing.T) {
+	state := NewState()
+	uri := "file:///does/not/exist/COMMIT_EDITMSG"
+	state.OpenDocument(uri, "")
+
+	snippets := func() []lsp.CompletionItem {
+		result := []lsp.CompletionItem{}
+		for _, item := range state.TextDocumentCompletion(1, uri, lsp.Position{}).Result {
+			if item.InsertTextFormat == lsp.SnippetFormat {
+				result = append(result, item)
+			}
+		}
+		return result
+	}
+
+	if items := snippets(); len(items) != 0 {
+		t.Fatalf("snippets should only be offered if the client supports them, got %d", len(items))
+	}
+
+	state.Capabilities.TextDocument.Completion.CompletionItem.SnippetSupport = true
+	items := snippets()
+	if len(items) != len(lsp.Prefixes)+1 {
+		t.Fatalf("there should be a snippet per type and the breaking change template, got %d", len(items))
+	}
+	if items[3].InsertText != "feat(${1:scope}): ${2:description}" || items[3].FilterText != "feat" {
+		t.Fatalf("unexpected feat snippet %+v", items[3])
+	}
+}
+
+func TestCompletionRange(t *testing.T) {
+	cases := []struct {
+		document string
+		position lsp.Position
+		expected lsp.Range
+		lineEnd  bool
+	}{
+		{"fe(api): x", lsp.Position{Line: 0, Character: 2}, LineRange(0, 0, 2), false},
+		{"fe", lsp.Position{Line: 0, Character: 1}, LineRange(0, 0, 2), true},
+		{"", lsp.Position{Line: 0, Character: 0}, LineRange(0, 0, 0), true},
+		{"feat(ap): x", lsp.Position{Line: 0, Character: 7}, LineRange(0, 5, 7), false},
+		{"feat(my-ap", lsp.Position{Line: 0, Character: 10}, LineRange(0, 5, 10), true},
+		{"fix: x\n\nRe", lsp.Position{Line: 2, Character: 2}, LineRange(2, 0, 2), true},
+		{"fix: x\n\nSig: y", lsp.Position{Line: 2, Character: 2}, LineRange(2, 0, 5), false},
+	}
+
+	for idx, tc := range cases {
+		context := getCompletionContext(tc.document, tc.position)
+		actual, lineEnd := completionRange(tc.document, tc.position, context)
+		if actual != tc.expected || lineEnd != tc.lineEnd {
+			t.Fatalf("Test case %d failed. Got %+v %t - Exp %+v %t", idx, actual, lineEnd, tc.expected, tc.lineEnd)
+		}
+	}
+
+	state := NewState()
+	uri := "file:///does/not/exist/COMMIT_EDITMSG"
+	state.OpenDocument(uri, "fe(api): x")
+	for _, item := range state.TextDocumentCompletion(1, uri, lsp.Position{Line: 0, Character: 2}).Result {
+		if item.TextEdit == nil || item.TextEdit.Range != LineRange(0, 0, 2) || item.TextEdit.NewText != item.Label {
+			t.Fatalf("every item should replace the typed fe, got %+v", item)
+		}
+	}
+}
+
+func TestResolveCompletion(t *testing.T) {
+	state := NewState()
+	uri := "file:///does/not/exist/COMMIT_EDITMSG"
+	state.OpenDocument(uri, "fix: x\n\n")
+
+	items := state.TextDocumentCompletion(1, uri, lsp.Position{Line: 0, Character: 1}).Result
+	items = append(items, state.TextDocumentCompletion(1, uri, lsp.Position{Line: 2, Character: 0}).Result...)
+	for _, item := range items {
+		if item.Documentation != nil {
+			t.Fatalf("the documentation should only be sent on resolve, got %+v", item)
+		}
+		resolved := state.ResolveCompletion(2, item).Result
+		if resolved.Documentation == nil || resolved.Documentation.Kind != lsp.PlainText {
+			t.Fatalf("the item should be resolved with plain text documentation, got %+v", resolved)
+		}
+	}
+
+	state.Capabilities.TextDocument.Completion.CompletionItem.DocumentationFormat = []string{lsp.Markdown}
+	resolved := state.ResolveCompletion(3, items[0]).Result
+	if resolved.Documentation.Kind != lsp.Markdown || resolved.Documentation.Value != lsp.TypeDocs["build"].Markdown("build") {
+		t.Fatalf("the type documentation should be markdown, got %+v", resolved.Documentation)
+	}
+}
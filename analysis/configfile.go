@@ -0,0 +1,365 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/lsp"
+	"path"
+	"strings"
+)
+
+// configDocument is an open config file of cc-lsp, name tells whether it is
+// the JSON or the YAML config
+type configDocument struct {
+	name string
+	text string
+}
+
+// configFileName returns the config file the document is read as. The
+// language of the client decides over the extension, other languages than
+// JSON and YAML are no config files.
+func configFileName(languageID, uri string) (string, bool) {
+	base := path.Base(uri)
+	if base != config.File && base != config.YAMLFile {
+		return "", false
+	}
+	switch languageID {
+	case "json", "jsonc":
+		return config.File, true
+	case "yaml":
+		return config.YAMLFile, true
+	case "":
+		return base, true
+	}
+	return "", false
+}
+
+// OpenTextDocument opens a commit message or a config file of cc-lsp
+func (s *State) OpenTextDocument(item lsp.TextDocumentItem) []lsp.Diagnostic {
+	if name, ok := configFileName(item.LanguageID, item.URI); ok {
+		document := configDocument{name: name, text: item.Text}
+		s.configDocuments[item.URI] = document
+		return configDiagnostics(document)
+	}
+	return s.OpenDocument(item.URI, item.Text)
+}
+
+// configDiagnostics reports the problems of the config, problems that only
+// know their key are put on the line the key is on
+func configDiagnostics(document configDocument) []lsp.Diagnostic {
+	lines := strings.Split(document.text, "\n")
+	diagnostics := []lsp.Diagnostic{}
+	for _, problem := range config.Check(document.name, []byte(document.text)) {
+		r := LineRange(0, 0, 0)
+		if problem.Line >= 0 && problem.Line < len(lines) {
+			line := lines[problem.Line]
+			r = LineRange(problem.Line, len(line)-len(strings.TrimLeft(line, " \t")), len(line))
+		} else if found, ok := findConfigKey(lines, problem.Key); ok {
+			r = found
+		}
+		severity := 1
+		if problem.Level == config.Warning {
+			severity = 2
+		}
+		diagnostics = append(diagnostics, lsp.Diagnostic{
+			Range:    r,
+			Severity: severity,
+			Source:   "cc-lint",
+			Message:  problem.Message,
+		})
+	}
+	return diagnostics
+}
+
+// findConfigKey returns the range of the first key with the name, quoted in
+// JSON and YAML or plain in YAML
+func findConfigKey(lines []string, key string) (lsp.Range, bool) {
+	if key == "" {
+		return lsp.Range{}, false
+	}
+	for number, line := range lines {
+		for _, quoted := range []string{`"` + key + `"`, `'` + key + `'`} {
+			if idx := strings.Index(line, quoted); idx >= 0 && strings.HasPrefix(strings.TrimLeft(line[idx+len(quoted):], " "), ":") {
+				return LineRange(number, idx+1, idx+1+len(key)), true
+			}
+		}
+		text := strings.TrimLeft(line, " ")
+		text = strings.TrimLeft(strings.TrimPrefix(text, "-"), " ")
+		if strings.HasPrefix(text, key+":") {
+			start := len(line) - len(text)
+			return LineRange(number, start, start+len(key)), true
+		}
+	}
+	return lsp.Range{}, false
+}
+
+// configContext is where the cursor is in a config file: at a key of the
+// parent mapping or at the value of the key, item is set for the items of a
+// sequence
+type configContext struct {
+	parent string
+	key    string
+	value  bool
+	item   bool
+}
+
+// getConfigContext finds the keys around the cursor by the indentation of
+// the YAML lines or the nesting of the JSON text
+func getConfigContext(document configDocument, position lsp.Position) configContext {
+	lines := strings.Split(document.text, "\n")
+	if position.Line < 0 || position.Line >= len(lines) {
+		return configContext{}
+	}
+	if document.name == config.YAMLFile {
+		return yamlContext(lines, position)
+	}
+	offset := 0
+	for _, line := range lines[:position.Line] {
+		offset += len(line) + 1
+	}
+	offset += min(position.Character, len(lines[position.Line]))
+	return jsonContext(document.text[:offset])
+}
+
+func yamlContext(lines []string, position lsp.Position) configContext {
+	prefix := lines[position.Line][:min(position.Character, len(lines[position.Line]))]
+	text := strings.TrimLeft(prefix, " ")
+	above := lines[:position.Line]
+	context := configContext{}
+
+	if item, ok := strings.CutPrefix(text, "- "); ok {
+		// an item of a sequence is the value of the key above it, the
+		// items of packages are mappings
+		key, number := yamlParent(above, len(prefix)-len(text), true)
+		if _, mapping := configKeys[key]; !mapping {
+			context.parent, _ = yamlParent(above[:max(number, 0)], yamlIndent(above, number), false)
+			context.key, context.value, context.item = key, true, true
+			return context
+		}
+		context.parent = key
+		text = item
+	} else {
+		context.parent, _ = yamlParent(above, len(prefix)-len(text), false)
+	}
+	if key, _, ok := strings.Cut(text, ":"); ok {
+		context.key = strings.Trim(key, `"' `)
+		context.value = true
+	}
+	return context
+}
+
+func yamlIndent(lines []string, number int) int {
+	if number < 0 {
+		return 0
+	}
+	return len(lines[number]) - len(strings.TrimLeft(lines[number], " "))
+}
+
+// yamlParent returns the key of the mapping a line with the indent is in and
+// the line of the key, -1 at the top level. With items set a key with the
+// same indent can hold a sequence.
+func yamlParent(lines []string, indent int, items bool) (string, int) {
+	for number := len(lines) - 1; number >= 0; number-- {
+		text := strings.TrimLeft(lines[number], " ")
+		lineIndent := len(lines[number]) - len(text)
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "- ") {
+			continue
+		}
+		if lineIndent > indent || (lineIndent == indent && !items) {
+			continue
+		}
+		if key, rest, ok := strings.Cut(text, ":"); ok && strings.TrimSpace(rest) == "" {
+			return strings.Trim(key, `"' `), number
+		}
+		if lineIndent < indent {
+			break
+		}
+	}
+	return "", -1
+}
+
+// jsonContext scans the JSON text in front of the cursor, every open object
+// or array remembers the key it is the value of
+func jsonContext(text string) configContext {
+	type open struct {
+		key   string
+		array bool
+	}
+	stack := []open{}
+	last, pending := "", ""
+	value := false
+	for idx := 0; idx < len(text); idx++ {
+		switch text[idx] {
+		case '"':
+			end := idx + 1
+			for end < len(text) && text[end] != '"' {
+				if text[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(text) {
+				// the cursor is inside of the string
+				idx = len(text)
+				continue
+			}
+			last = text[idx+1 : end]
+			idx = end
+		case ':':
+			pending = last
+			value = true
+		case '{', '[':
+			stack = append(stack, open{key: pending, array: text[idx] == '['})
+			value = false
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			value = false
+		case ',':
+			value = false
+		}
+	}
+
+	if len(stack) == 0 {
+		return configContext{}
+	}
+	top := stack[len(stack)-1]
+	parent := ""
+	if len(stack) > 1 {
+		parent = stack[len(stack)-2].key
+	}
+	if top.array {
+		return configContext{parent: parent, key: top.key, value: true, item: true}
+	}
+	if value {
+		return configContext{parent: top.key, key: pending, value: true}
+	}
+	return configContext{parent: top.key}
+}
+
+// configKeys are the keys of the mappings in the config file
+var configKeys = map[string][]string{
+	"":         config.Options,
+	"rules":    config.RuleNames,
+	"format":   {"wrapColumn"},
+	"scopes":   {"providers"},
+	"packages": {"name", "path", "scopes"},
+}
+
+// configCompletions returns the keys or the values that fit the context
+func configCompletions(document configDocument, context configContext) []lsp.CompletionItem {
+	const (
+		property = 10
+		value    = 12
+	)
+	items := []lsp.CompletionItem{}
+	quote := func(text string) string {
+		if document.name == config.YAMLFile {
+			return text
+		}
+		return `"` + text + `"`
+	}
+	add := func(label, insert string, kind int, detail string) {
+		item := lsp.CompletionItem{Label: label, InsertText: insert, Kind: kind, Detail: detail}
+		if _, ok := config.Documentation(label); ok {
+			item.Data = completionData{Kind: configItem, Name: label}.encode()
+		}
+		items = append(items, item)
+	}
+
+	if !context.value {
+		for _, key := range configKeys[context.parent] {
+			detail := "option"
+			if context.parent == "rules" {
+				detail = "rule"
+			}
+			add(key, quote(key), property, detail)
+		}
+		return items
+	}
+
+	switch {
+	case context.key == "preset":
+		for _, preset := range config.PresetNames {
+			add(preset, quote(preset), value, "preset")
+		}
+	case context.key == "providers":
+		for _, provider := range config.ScopeProviders {
+			add(provider, quote(provider), value, "scope provider")
+		}
+	case context.parent == "rules" && !context.item:
+		always := quote("always")
+		for level, detail := range []string{"disabled", "warning", "error"} {
+			label := "[" + string(rune('0'+level)) + ", " + always + "]"
+			add(label, label, value, detail)
+		}
+	}
+	return items
+}
+
+// configWordBounds returns the start and the end of the key or the value at
+// the character, unlike words in messages they contain - and digits
+func configWordBounds(line string, character int) (int, int) {
+	isWord := func(ch byte) bool {
+		return ch == '-' || ch == '_' || ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+	}
+	character = min(max(character, 0), len(line))
+	start, end := character, character
+	for start > 0 && isWord(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isWord(line[end]) {
+		end++
+	}
+	return start, end
+}
+
+func (s *State) configCompletion(id int, document configDocument, position lsp.Position) lsp.CompletionResponse {
+	items := configCompletions(document, getConfigContext(document, position))
+	lines := strings.Split(document.text, "\n")
+	if position.Line >= 0 && position.Line < len(lines) {
+		line := lines[position.Line]
+		start, end := configWordBounds(line, position.Character)
+		// the quotes the client typed are replaced too
+		if document.name != config.YAMLFile && start > 0 && line[start-1] == '"' {
+			start--
+			if end < len(line) && line[end] == '"' {
+				end++
+			}
+		}
+		setTextEdits(items, LineRange(position.Line, start, end))
+	}
+	return lsp.CompletionResponse{
+		Response: lsp.Response{
+			RPC: "2.0",
+			ID:  &id,
+		},
+		Result: items,
+	}
+}
+
+func (s *State) configHover(id int, document configDocument, position lsp.Position) lsp.HoverResponse {
+	response := lsp.HoverResponse{
+		Response: lsp.Response{
+			RPC: "2.0",
+			ID:  &id,
+		},
+	}
+	lines := strings.Split(document.text, "\n")
+	if position.Line < 0 || position.Line >= len(lines) {
+		return response
+	}
+	line := lines[position.Line]
+	start, end := configWordBounds(line, position.Character)
+	name := line[start:end]
+	doc, ok := config.Documentation(name)
+	if !ok {
+		return response
+	}
+	wordRange := LineRange(position.Line, start, end)
+	response.Result = &lsp.HoverResult{
+		Contents: s.markup("**"+name+"**\n\n"+doc, name+": "+doc),
+		Range:    &wordRange,
+	}
+	return response
+}
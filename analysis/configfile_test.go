@@ -0,0 +1,132 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/lsp"
+	"testing"
+)
+
+func TestConfigFileName(t *testing.T) {
+	cases := []struct {
+		languageID string
+		uri        string
+		expected   string
+		ok         bool
+	}{
+		{"json", "file:///repo/.cc-lsp.json", config.File, true},
+		{"yaml", "file:///repo/.cc-lsp.yaml", config.YAMLFile, true},
+		{"", "file:///repo/.cc-lsp.yaml", config.YAMLFile, true},
+		{"yaml", "file:///repo/.cc-lsp.json", config.YAMLFile, true},
+		{"gitcommit", "file:///repo/.cc-lsp.json", "", false},
+		{"json", "file:///repo/package.json", "", false},
+	}
+
+	for idx, tc := range cases {
+		actual, ok := configFileName(tc.languageID, tc.uri)
+		if actual != tc.expected || ok != tc.ok {
+			t.Fatalf("Test case %d failed. Got %q %t - Exp %q %t", idx, actual, ok, tc.expected, tc.ok)
+		}
+	}
+}
+
+func TestConfigDiagnostics(t *testing.T) {
+	state := NewState()
+	uri := "file:///does/not/exist/.cc-lsp.yaml"
+	diagnostics := state.OpenTextDocument(lsp.TextDocumentItem{URI: uri, LanguageID: "yaml", Text: "preset: strict\nrules:\n  'scope-enum': [3]\n  no-such-rule: [2]\n"})
+	expected := []lsp.Range{LineRange(3, 2, 14), LineRange(2, 3, 13)}
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("expected a diagnostic for the unknown rule and the level, got %+v", diagnostics)
+	}
+	for idx, r := range expected {
+		if diagnostics[idx].Range != r || diagnostics[idx].Severity != 1 {
+			t.Fatalf("Test case %d failed. Got %+v - Exp %+v", idx, diagnostics[idx], r)
+		}
+	}
+
+	diagnostics = state.UpdateDocument(uri, "rules:\n  scope-enum: [2, always\n")
+	if len(diagnostics) != 1 || diagnostics[0].Range != LineRange(1, 2, 24) {
+		t.Fatalf("the syntax error should be on its line, got %+v", diagnostics)
+	}
+	if _, ok := state.Documents[uri]; ok {
+		t.Fatal("a config file is no commit message")
+	}
+	if lenses := state.CodeLens(1, uri).Result; len(lenses) != 0 {
+		t.Fatalf("a config file should have no commit message features, got %+v", lenses)
+	}
+
+	diagnostics = state.OpenTextDocument(lsp.TextDocumentItem{URI: "file:///does/not/exist/.cc-lsp.json", LanguageID: "json", Text: "{\n  \"formt\": {}\n}"})
+	if len(diagnostics) != 1 || diagnostics[0].Range != LineRange(1, 3, 8) || diagnostics[0].Severity != 2 {
+		t.Fatalf("unknown options should be warnings on their key, got %+v", diagnostics)
+	}
+}
+
+func TestGetConfigContext(t *testing.T) {
+	yaml := "preset: \nrules:\n  he\n  scope-enum: \nscopes:\n  providers:\n  - \npackages:\n  - na\n    pa\n"
+	json := `{"preset": "", "rules": {"he": [2, "always"], "scope-enum": }, "scopes": {"providers": ["go", ]}, "packages": [{"na`
+	cases := []struct {
+		name     string
+		position lsp.Position
+		expected configContext
+	}{
+		{config.YAMLFile, lsp.Position{Line: 0, Character: 3}, configContext{}},
+		{config.YAMLFile, lsp.Position{Line: 0, Character: 8}, configContext{key: "preset", value: true}},
+		{config.YAMLFile, lsp.Position{Line: 2, Character: 4}, configContext{parent: "rules"}},
+		{config.YAMLFile, lsp.Position{Line: 3, Character: 14}, configContext{parent: "rules", key: "scope-enum", value: true}},
+		{config.YAMLFile, lsp.Position{Line: 6, Character: 4}, configContext{parent: "scopes", key: "providers", value: true, item: true}},
+		{config.YAMLFile, lsp.Position{Line: 8, Character: 6}, configContext{parent: "packages"}},
+		{config.YAMLFile, lsp.Position{Line: 9, Character: 6}, configContext{parent: "packages"}},
+		{config.File, lsp.Position{Line: 0, Character: 4}, configContext{}},
+		{config.File, lsp.Position{Line: 0, Character: 12}, configContext{key: "preset", value: true}},
+		{config.File, lsp.Position{Line: 0, Character: 28}, configContext{parent: "rules"}},
+		{config.File, lsp.Position{Line: 0, Character: 38}, configContext{parent: "rules", key: "he", value: true, item: true}},
+		{config.File, lsp.Position{Line: 0, Character: 60}, configContext{parent: "rules", key: "scope-enum", value: true}},
+		{config.File, lsp.Position{Line: 0, Character: 94}, configContext{parent: "scopes", key: "providers", value: true, item: true}},
+		{config.File, lsp.Position{Line: 0, Character: 117}, configContext{parent: "packages"}},
+	}
+
+	for idx, tc := range cases {
+		text := yaml
+		if tc.name == config.File {
+			text = json
+		}
+		if actual := getConfigContext(configDocument{name: tc.name, text: text}, tc.position); actual != tc.expected {
+			t.Fatalf("Test case %d failed. Got %+v - Exp %+v", idx, actual, tc.expected)
+		}
+	}
+}
+
+func TestConfigCompletionAndHover(t *testing.T) {
+	state := NewState()
+	uri := "file:///does/not/exist/.cc-lsp.json"
+	state.OpenTextDocument(lsp.TextDocumentItem{URI: uri, LanguageID: "json", Text: "{\"rules\": {\"hea\": }, \"preset\": }"})
+
+	items := state.TextDocumentCompletion(1, uri, lsp.Position{Line: 0, Character: 15}).Result
+	if len(items) != len(config.RuleNames) {
+		t.Fatalf("the rule names should be offered, got %+v", items)
+	}
+	edit := items[3].TextEdit
+	if edit == nil || edit.NewText != `"header-max-length"` || edit.Range != LineRange(0, 11, 16) {
+		t.Fatalf("the quoted key should be replaced, got %+v", edit)
+	}
+	if resolved := state.ResolveCompletion(2, items[3]).Result; resolved.Documentation == nil {
+		t.Fatal("the rule documentation should be added on resolve")
+	}
+
+	items = state.TextDocumentCompletion(1, uri, lsp.Position{Line: 0, Character: 18}).Result
+	if len(items) != 3 || items[2].Label != `[2, "always"]` {
+		t.Fatalf("the levels should be offered as rule values, got %+v", items)
+	}
+	items = state.TextDocumentCompletion(1, uri, lsp.Position{Line: 0, Character: 31}).Result
+	if len(items) != len(config.PresetNames) || items[1].TextEdit.NewText != `"strict"` {
+		t.Fatalf("the presets should be offered, got %+v", items)
+	}
+
+	state.UpdateDocument(uri, `{"rules": {"header-max-length": [1]}}`)
+	hover := state.Hover(1, uri, lsp.Position{Line: 0, Character: 20}).Result
+	if hover == nil || *hover.Range != LineRange(0, 12, 29) || hover.Contents.Value != "header-max-length: "+config.RuleDocs[config.HeaderMaxLength] {
+		t.Fatalf("the rule documentation should be shown, got %+v", hover)
+	}
+	if hover := state.Hover(1, uri, lsp.Position{Line: 0, Character: 33}).Result; hover != nil {
+		t.Fatalf("a level has no documentation, got %+v", hover)
+	}
+}
@@ -0,0 +1,268 @@
+package analysis
+
+import (
+	"cc-lsp/conventional"
+	"cc-lsp/lsp"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// bulletRegexp matches the marker of a list item and the indentation in front
+var bulletRegexp = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+`)
+
+// formatMessage returns the document with the message in its canonical
+// layout. Only the message above the first comment is formatted, the
+// comments and the diff below stay as they are.
+func formatMessage(document string, column int) string {
+	lines := strings.Split(document, "\n")
+	end := messageEnd(lines)
+	commit := conventional.Parse(strings.Join(lines[:end], "\n"))
+	if commit.Header == nil {
+		return document
+	}
+
+	formatted := []string{formatHeader(commit, strings.TrimRight(lines[commit.Header.Line], "\r"))}
+	if commit.Body != "" {
+		// the raw lines keep the indentation of the first line, the body
+		// of the commit is trimmed
+		body := lines[commit.BodyRange.Start.Line : commit.BodyRange.End.Line+1]
+		formatted = append(formatted, "")
+		formatted = append(formatted, formatBody(strings.Join(body, "\n"), column)...)
+	}
+	if len(commit.Footers) > 0 {
+		formatted = append(formatted, "")
+		for _, footer := range commit.Footers {
+			formatted = append(formatted, formatFooter(footer)...)
+		}
+	}
+
+	// one blank line separates the message from the comments
+	if end < len(lines) {
+		formatted = append(formatted, "")
+		formatted = append(formatted, lines[end:]...)
+	} else if strings.HasSuffix(document, "\n") {
+		formatted = append(formatted, "")
+	}
+	return strings.Join(formatted, "\n")
+}
+
+// unspacedHeaderRegexp matches a header without the space behind the colon,
+// which the parser does not take as conventional. URLs are no such header.
+var unspacedHeaderRegexp = regexp.MustCompile(`^([A-Za-z]+)(\([^()]*\))?(!?):([^\s/].*)$`)
+
+// formatHeader lowercases the type and puts a single space behind the colon
+func formatHeader(commit conventional.Commit, line string) string {
+	header := commit.Header
+	if !commit.Conventional && !header.UnknownType {
+		line = strings.TrimSpace(line)
+		if match := unspacedHeaderRegexp.FindStringSubmatch(line); match != nil {
+			return strings.ToLower(match[1]) + match[2] + match[3] + ": " + match[4]
+		}
+		return line
+	}
+	text := func(r lsp.Range) string {
+		return line[r.Start.Character:r.End.Character]
+	}
+	formatted := strings.ToLower(text(header.Type))
+	if scope := text(header.Scope); scope != "" {
+		formatted += "(" + scope + ")"
+	}
+	return formatted + text(header.Breaking) + ": " + strings.TrimSpace(text(header.Description))
+}
+
+// formatBody wraps the long lines of the body and collapses runs of blank
+// lines, code blocks are left alone
+func formatBody(body string, column int) []string {
+	formatted := []string{}
+	fenced := false
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			formatted = append(formatted, line)
+			continue
+		}
+		if fenced || isIndentedCode(line) {
+			formatted = append(formatted, line)
+			continue
+		}
+		if line == "" && len(formatted) > 0 && formatted[len(formatted)-1] == "" {
+			continue
+		}
+		formatted = append(formatted, wrapLine(line, column)...)
+	}
+	return formatted
+}
+
+// isIndentedCode reports whether the line is a code block indented by a tab
+// or four spaces, indented list items are no code
+func isIndentedCode(line string) bool {
+	indented := strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "    ")
+	return indented && !bulletRegexp.MatchString(line)
+}
+
+// wrapLine breaks the line at the spaces before the column, the width is
+// counted in characters. Continuation lines keep the indentation of the line
+// and of its list marker, words like URLs that are longer than the column are
+// never broken.
+func wrapLine(line string, column int) []string {
+	if utf8.RuneCountInString(line) <= column {
+		return []string{line}
+	}
+	prefix := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	indent := prefix
+	if marker := bulletRegexp.FindString(line); marker != "" {
+		prefix = marker
+		indent = strings.Repeat(" ", len(marker))
+	}
+
+	lines := []string{}
+	current, empty := prefix, true
+	width := utf8.RuneCountInString(current)
+	for _, word := range strings.Fields(line[len(prefix):]) {
+		length := utf8.RuneCountInString(word)
+		switch {
+		case empty:
+			current += word
+			width += length
+		case width+1+length > column:
+			lines = append(lines, current)
+			current = indent + word
+			width = len(indent) + length
+		default:
+			current += " " + word
+			width += 1 + length
+		}
+		empty = false
+	}
+	return append(lines, current)
+}
+
+// formatFooter writes the first line of the footer as `Token: value`, the
+// lines that continue the value stay as they are
+func formatFooter(footer conventional.Footer) []string {
+	values := strings.Split(footer.Value, "\n")
+	first := strings.TrimSpace(values[0])
+	if footer.Separator == " #" {
+		// the value of `Token #value` footers starts behind the #
+		first = "#" + first
+	}
+	lines := []string{footer.Token + ": " + first}
+	for _, value := range values[1:] {
+		lines = append(lines, strings.TrimRight(value, " \t\r"))
+	}
+	return lines
+}
+
+// lineEdits returns the edits that turn the old text into the new one, only
+// the lines that differ are replaced
+func lineEdits(old, new string) []lsp.TextEdit {
+	before, after := strings.Split(old, "\n"), strings.Split(new, "\n")
+
+	// the lines in front of and behind the changes, like the diff below the
+	// message, are skipped so the table only holds the changed lines
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+	changed, added := before[prefix:len(before)-suffix], after[prefix:len(after)-suffix]
+
+	// longest common subsequence of the lines, from the back
+	common := make([][]int, len(changed)+1)
+	for i := range common {
+		common[i] = make([]int, len(added)+1)
+	}
+	for i := len(changed) - 1; i >= 0; i-- {
+		for j := len(added) - 1; j >= 0; j-- {
+			if changed[i] == added[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	edits := []lsp.TextEdit{}
+	i, j := 0, 0
+	for i < len(changed) || j < len(added) {
+		if i < len(changed) && j < len(added) && changed[i] == added[j] {
+			i++
+			j++
+			continue
+		}
+		// collect the differing lines up to the next common one
+		start, from := i, j
+		for i < len(changed) || j < len(added) {
+			if i < len(changed) && j < len(added) && changed[i] == added[j] {
+				break
+			}
+			if j == len(added) || i < len(changed) && common[i+1][j] >= common[i][j+1] {
+				i++
+			} else {
+				j++
+			}
+		}
+		edits = append(edits, replaceLines(before, prefix+start, prefix+i, added[from:j]))
+	}
+	return edits
+}
+
+// replaceLines returns the edit that replaces the lines start to end of the
+// old text with the given lines
+func replaceLines(before []string, start, end int, lines []string) lsp.TextEdit {
+	text := strings.Join(lines, "\n")
+	if end < len(before) {
+		// the edit ends at the start of the next line that stays
+		if len(lines) > 0 {
+			text += "\n"
+		}
+		return lsp.TextEdit{
+			Range:   lsp.Range{Start: lsp.Position{Line: start}, End: lsp.Position{Line: end}},
+			NewText: text,
+		}
+	}
+	// at the end of the text the new line in front of the lines is replaced
+	from := lsp.Position{Line: 0, Character: 0}
+	if start > 0 {
+		from = lsp.Position{Line: start - 1, Character: len(before[start-1])}
+		if len(lines) > 0 {
+			text = "\n" + text
+		}
+	}
+	to := from
+	if end > start {
+		to = lsp.Position{Line: end - 1, Character: len(before[end-1])}
+	}
+	return lsp.TextEdit{
+		Range:   lsp.Range{Start: from, End: to},
+		NewText: text,
+	}
+}
+
+// wrapColumn returns the wrap column configured for the document, the one
+// of the editor settings outside of a repository
+func (s *State) wrapColumn(uri string) int {
+	if repo, err := repoForURI(uri); err == nil {
+		if cfg, err := s.config(repo); err == nil {
+			return cfg.Format.Column()
+		}
+	}
+	return s.settings().Format.Column()
+}
+
+func (s *State) Formatting(id int, uri string) lsp.DocumentFormattingResponse {
+	document := s.Documents[uri]
+	return lsp.DocumentFormattingResponse{
+		Response: lsp.Response{
+			RPC: "2.0",
+			ID:  &id,
+		},
+		Result: lineEdits(document, formatMessage(document, s.wrapColumn(uri))),
+	}
+}
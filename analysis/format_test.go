@@ -0,0 +1,86 @@
+package analysis
+
+import (
+	"cc-lsp/lsp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFormatMessage(t *testing.T) {
+	// 69 characters but 77 bytes
+	german := "Zahlen für die Übergänge ändern sich, Umlaute wie äöü werden gezählt."
+	long := "The list endpoints return pages now, clients that relied on getting every item at once have to follow the next links."
+	cases := []struct {
+		document string
+		expected string
+	}{
+		{"", ""},
+		{"Feat(api):   add paging  ", "feat(api): add paging"},
+		{"fix: x\nbody\n\n\n\nRefs #12\nCloses:   #3\n", "fix: x\n\nbody\n\nRefs: #12\nCloses: #3\n"},
+		{"fix: x\n\n" + long, "fix: x\n\nThe list endpoints return pages now, clients that relied on getting\nevery item at once have to follow the next links."},
+		{"fix: x\n\n- " + long, "fix: x\n\n- The list endpoints return pages now, clients that relied on getting\n  every item at once have to follow the next links."},
+		{"fix: x\n\n```\n" + long + "\n```\n\n    " + long, "fix: x\n\n```\n" + long + "\n```\n\n    " + long},
+		{"fix: x\n\nsee https://example.com/" + strings.Repeat("a", 80), "fix: x\n\nsee\nhttps://example.com/" + strings.Repeat("a", 80)},
+		{"fix: x\n# comment\n" + long, "fix: x\n\n# comment\n" + long},
+		{"not conventional \n\nbody", "not conventional\n\nbody"},
+		{"fix: x\n\n    go test ./...", "fix: x\n\n    go test ./..."},
+		{"Feat:add x", "feat: add x"},
+		{"Fix(api)!:drop v1", "fix(api)!: drop v1"},
+		{"https://example.com/issues/12", "https://example.com/issues/12"},
+		{"fix: x\n\n" + german, "fix: x\n\n" + german},
+		{"fix: x\n\n" + german + " Die Grenze liegt bei zweiundsiebzig Zeichen, nicht Bytes.", "fix: x\n\n" + german + "\nDie Grenze liegt bei zweiundsiebzig Zeichen, nicht Bytes."},
+		{"fix: x\n\n  - item one\n  - item two", "fix: x\n\n  - item one\n  - item two"},
+	}
+
+	for idx, tc := range cases {
+		if actual := formatMessage(tc.document, 72); actual != tc.expected {
+			t.Fatalf("Test case %d failed.\nGot %q\nExp %q", idx, actual, tc.expected)
+		}
+	}
+}
+
+// applyEdits applies edits that do not overlap, as a client would
+func applyEdits(text string, edits []lsp.TextEdit) string {
+	lines := strings.Split(text, "\n")
+	offset := func(position lsp.Position) int {
+		count := 0
+		for _, line := range lines[:position.Line] {
+			count += len(line) + 1
+		}
+		return count + position.Character
+	}
+	edits = slices.Clone(edits)
+	slices.Reverse(edits)
+	for _, edit := range edits {
+		text = text[:offset(edit.Range.Start)] + edit.NewText + text[offset(edit.Range.End):]
+	}
+	return text
+}
+
+func TestLineEdits(t *testing.T) {
+	// a diff below the message is too long for a table of every line
+	diff := strings.Repeat("+a line of the diff\n", 100000)
+	cases := []struct {
+		old, new string
+		edits    int
+	}{
+		{"a\nb\nc", "a\nb\nc", 0},
+		{"a\nb\nc", "a\nB\nc", 1},
+		{"A\nb\nC", "a\nb\nc", 2},
+		{"a\nb", "a\nb\nc\n", 1},
+		{"a\nb\nc\n", "a", 1},
+		{"a", "b", 1},
+		{"a\n\n\nb\n# c", "a\n\nb\n\n# c", 2},
+		{"a\nb\na", "a", 1},
+		{"a", "a\nb\na", 1},
+		{"Fix: x\n" + diff, "fix: x\n" + diff, 1},
+	}
+
+	for idx, tc := range cases {
+		edits := lineEdits(tc.old, tc.new)
+		if actual := applyEdits(tc.old, edits); actual != tc.new || len(edits) != tc.edits {
+			t.Fatalf("Test case %d failed. Got %q with %d edits - Exp %q with %d", idx, actual, len(edits), tc.new, tc.edits)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package analysis
+
+import (
+	"cc-lsp/git"
+	"cc-lsp/goapi"
+	"path"
+	"sort"
+	"strings"
+)
+
+// apiChange is a change of the exported API of a package with staged Go files
+type apiChange struct {
+	goapi.Change
+	// file is the file that declared the identifier, relative to the repository root
+	file string
+}
+
+// isAPIFile reports whether the file can declare exported API: test files,
+// internal packages and files that are not Go are skipped
+func isAPIFile(file string) bool {
+	if path.Ext(file) != ".go" || strings.HasSuffix(file, "_test.go") {
+		return false
+	}
+	for _, dir := range strings.Split(path.Dir(file), "/") {
+		if dir == "internal" || dir == "testdata" || dir == "vendor" {
+			return false
+		}
+	}
+	return true
+}
+
+// stagedAPIChanges compares the exported API of every package with staged Go
+// files between HEAD and the staged content. The API of a package is the API
+// of all its staged files, so moving an identifier between them is no change.
+func stagedAPIChanges(repo git.Repo, staged []string) []apiChange {
+	packages := map[string][]string{}
+	for _, file := range staged {
+		if isAPIFile(file) {
+			packages[path.Dir(file)] = append(packages[path.Dir(file)], file)
+		}
+	}
+
+	changes := []apiChange{}
+	for _, files := range packages {
+		old, new := goapi.API{}, goapi.API{}
+		origin := map[string]string{}
+		name := ""
+		for _, file := range files {
+			// new files have no HEAD version and deleted files no staged version
+			if src, err := repo.Show("HEAD", file); err == nil {
+				api := goapi.API{}
+				if pkg, err := api.Parse(file, src); err == nil {
+					name = pkg
+					for identifier, signature := range api {
+						old[identifier] = signature
+						origin[identifier] = file
+					}
+				}
+			}
+			if src, err := repo.Show("", file); err == nil {
+				new.Parse(file, src)
+			}
+		}
+		// commands have no API
+		if name == "" || name == "main" {
+			continue
+		}
+
+		for _, change := range goapi.Diff(old, new) {
+			file := origin[change.Name]
+			change.Name = name + "." + change.Name
+			changes = append(changes, apiChange{Change: change, file: file})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
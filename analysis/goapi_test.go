@@ -0,0 +1,67 @@
+package analysis
+
+import (
+	"cc-lsp/lsp"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGoAPIBreaking(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+	write := func(name, content string) {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "--quiet")
+	write("shop/cart.go", "package shop\n\nfunc Checkout() error { return nil }\n\nfunc Total() int { return 0 }\n")
+	write("main.go", "package main\n\nfunc Run() {}\n")
+	git("add", ".")
+	git("commit", "--quiet", "--message", "feat: init")
+	write("shop/cart.go", "package shop\n\nfunc Total() int64 { return 0 }\n")
+	write("main.go", "package main\n")
+	git("add", ".")
+
+	uri := "file://" + filepath.ToSlash(dir) + "/.git/COMMIT_EDITMSG"
+	state := NewState()
+	diagnostics := state.OpenDocument(uri, "feat(shop): faster checkout\n")
+	var diagnostic *lsp.Diagnostic
+	for idx := range diagnostics {
+		if diagnostics[idx].Code == "go-api-breaking" {
+			diagnostic = &diagnostics[idx]
+		}
+	}
+	if diagnostic == nil || len(diagnostic.RelatedInformation) != 2 {
+		t.Fatalf("removing Checkout and changing Total should be reported, got %+v", diagnostics)
+	}
+
+	response := state.TextDocumentCodeAction(1, uri, lsp.CodeActionContext{Diagnostics: []lsp.Diagnostic{*diagnostic}})
+	if len(response.Result) != 1 {
+		t.Fatalf("there should be a code action to add the marker, got %+v", response.Result)
+	}
+	edit := response.Result[0].Edit.Changes[uri][0]
+	if edit.NewText != "!" || edit.Range.Start != (lsp.Position{Line: 0, Character: 10}) {
+		t.Fatalf("the ! should be inserted after the scope, got %+v", edit)
+	}
+
+	for _, message := range []string{"feat(shop)!: faster checkout", "feat(shop): faster checkout\n\nBREAKING CHANGE: Checkout is gone"} {
+		for _, diagnostic := range state.UpdateDocument(uri, message) {
+			if diagnostic.Code == "go-api-breaking" {
+				t.Fatalf("a breaking change should not be reported for %q", message)
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/conventional"
+	"cc-lsp/lsp"
+	"cc-lsp/release"
+	"fmt"
+)
+
+// inlayHints shows the semver bump behind the type and the length of the
+// header against the header-max-length limit at its end, a header over the
+// limit gets a warning sign
+func inlayHints(document string, cfg config.Config) []lsp.InlayHint {
+	hints := []lsp.InlayHint{}
+	commit := conventional.Parse(document)
+	header := commit.Header
+	if header == nil {
+		return hints
+	}
+
+	if commit.Conventional {
+		bump := release.BumpFor(commit)
+		hints = append(hints, lsp.InlayHint{
+			Position:    header.Type.End,
+			Label:       bump.String(),
+			Kind:        lsp.TypeHint,
+			Tooltip:     fmt.Sprintf("%s bump of the version", bump),
+			PaddingLeft: true,
+		})
+	}
+
+	if rule, ok := cfg.Rule(config.HeaderMaxLength); ok {
+		if limit, err := rule.Int(config.DefaultHeaderMaxLength); err == nil {
+			line, length := headerLine(document, header)
+			label := fmt.Sprintf("%d/%d", length, limit)
+			tooltip := fmt.Sprintf("The header is %d characters long, the limit is %d (%s)", length, limit, config.HeaderMaxLength)
+			if length > limit {
+				label = "⚠ " + label
+			}
+			hints = append(hints, lsp.InlayHint{
+				Position:    lsp.Position{Line: header.Line, Character: len(line)},
+				Label:       label,
+				Tooltip:     tooltip,
+				PaddingLeft: true,
+			})
+		}
+	}
+	return hints
+}
+
+// InlayHint returns the hints in the range
+func (s *State) InlayHint(id int, uri string, r lsp.Range) lsp.InlayHintResponse {
+	cfg := config.Config{}
+	if repo, err := repoForURI(uri); err == nil {
+		cfg, _ = s.config(repo)
+	}
+	hints := []lsp.InlayHint{}
+	for _, hint := range inlayHints(s.Documents[uri], cfg) {
+		if hint.Position.Line >= r.Start.Line && hint.Position.Line <= r.End.Line {
+			hints = append(hints, hint)
+		}
+	}
+	return lsp.InlayHintResponse{
+		Response: lsp.Response{
+			RPC: "2.0",
+			ID:  &id,
+		},
+		Result: hints,
+	}
+}
@@ -0,0 +1,46 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/conventional"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestInlayHints(t *testing.T) {
+	cases := []struct {
+		document string
+		rules    map[string]config.Rule
+		expected []string
+	}{
+		{"feat(api): add paging", nil, []string{"0:4 minor", "0:21 21/72"}},
+		{"# comment\nfix!: x", nil, []string{"1:3 major", "1:7 7/72"}},
+		{"docs: " + strings.Repeat("ä", 70), nil, []string{"0:4 none", "0:146 ⚠ 76/72"}},
+		{"not conventional", nil, []string{"0:16 16/72"}},
+		{"feat: x", map[string]config.Rule{config.HeaderMaxLength: {Level: config.Disabled}}, []string{"0:4 minor"}},
+		{"", nil, []string{}},
+	}
+
+	for idx, tc := range cases {
+		actual := []string{}
+		for _, hint := range inlayHints(tc.document, config.Config{Rules: tc.rules}) {
+			actual = append(actual, fmt.Sprintf("%d:%d %s", hint.Position.Line, hint.Position.Character, hint.Label))
+		}
+		if strings.Join(actual, ", ") != strings.Join(tc.expected, ", ") {
+			t.Fatalf("Test case %d failed. Got %v - Exp %v", idx, actual, tc.expected)
+		}
+	}
+}
+
+func TestCheckHeaderMaxLength(t *testing.T) {
+	rule := config.Rule{Level: config.Error, Applicable: "always", Value: []byte("10")}
+	document := "fix: äbcdefg"
+	diagnostics := checkHeaderMaxLength(rule, conventional.Parse(document), document)
+	if len(diagnostics) != 1 || diagnostics[0].Range != LineRange(0, 11, 13) || diagnostics[0].Severity != 1 {
+		t.Fatalf("the characters over the limit should be an error, got %+v", diagnostics)
+	}
+	if diagnostics := checkHeaderMaxLength(rule, conventional.Parse("fix: abc"), "fix: abc"); len(diagnostics) != 0 {
+		t.Fatalf("a short header should be fine, got %+v", diagnostics)
+	}
+}
@@ -0,0 +1,90 @@
+package analysis
+
+import (
+	"cc-lsp/conventional"
+	"cc-lsp/lsp"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// typedTypeRegexp matches the header up to the `(` or `:` that was just typed
+var typedTypeRegexp = regexp.MustCompile(`^([A-Za-z]+)(?:\(|(?:\([^()]*\))?!?:)$`)
+
+// onTypeEdits returns the small fixes for the character that was typed in
+// front of the position:
+//   - `(` and `:` lowercase the type, `:` adds the space behind it
+//   - a new line behind the header adds the blank line that separates the body
+//   - a space wraps the body line once it is longer than the column
+func onTypeEdits(document string, position lsp.Position, ch string, column int) []lsp.TextEdit {
+	edits := []lsp.TextEdit{}
+	lines := strings.Split(document, "\n")
+	if position.Line < 0 || position.Line >= len(lines) {
+		return edits
+	}
+	line := strings.TrimRight(lines[position.Line], "\r")
+	character := min(max(position.Character, 0), len(line))
+	before := line[:character]
+	commit := conventional.Parse(document)
+	if commit.Header == nil {
+		return edits
+	}
+
+	switch ch {
+	case "(", ":":
+		match := typedTypeRegexp.FindStringSubmatch(before)
+		if position.Line != commit.Header.Line || match == nil {
+			return edits
+		}
+		if typ := match[1]; typ != strings.ToLower(typ) {
+			edits = append(edits, lsp.TextEdit{Range: LineRange(position.Line, 0, len(typ)), NewText: strings.ToLower(typ)})
+		}
+		if ch == ":" && !strings.HasPrefix(line[character:], " ") {
+			edits = append(edits, lsp.TextEdit{Range: LineRange(position.Line, character, character), NewText: " "})
+		}
+	case "\n":
+		// only a new line at the end of the header, not one that splits it,
+		// and only if no blank line follows yet
+		separated := position.Line+1 < len(lines) && strings.TrimSpace(lines[position.Line+1]) == ""
+		if position.Line-1 == commit.Header.Line && strings.TrimSpace(line) == "" && !separated {
+			header := strings.TrimRight(lines[commit.Header.Line], "\r")
+			edits = append(edits, lsp.TextEdit{Range: LineRange(commit.Header.Line, len(header), len(header)), NewText: "\n"})
+		}
+	case " ":
+		if utf8.RuneCountInString(before) <= column || strings.TrimSpace(line[character:]) != "" || !isBodyLine(lines, commit, position.Line) {
+			return edits
+		}
+		wrapped := wrapLine(strings.TrimRight(before, " "), column)
+		if len(wrapped) > 1 {
+			edits = append(edits, lsp.TextEdit{Range: LineRange(position.Line, 0, character), NewText: strings.Join(wrapped, "\n") + " "})
+		}
+	}
+	return edits
+}
+
+// isBodyLine reports whether the line is part of the body and not in a
+// code block
+func isBodyLine(lines []string, commit conventional.Commit, number int) bool {
+	if commit.Body == "" || number < commit.BodyRange.Start.Line || number > commit.BodyRange.End.Line {
+		return false
+	}
+	fenced := false
+	for _, line := range lines[commit.BodyRange.Start.Line:number] {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+		}
+	}
+	line := strings.TrimRight(lines[number], "\r")
+	return !fenced && !strings.HasPrefix(line, "#") && !isIndentedCode(line)
+}
+
+func (s *State) OnTypeFormatting(id int, uri string, position lsp.Position, ch string) lsp.DocumentFormattingResponse {
+	return lsp.DocumentFormattingResponse{
+		Response: lsp.Response{
+			RPC: "2.0",
+			ID:  &id,
+		},
+		Result: onTypeEdits(s.Documents[uri], position, ch, s.wrapColumn(uri)),
+	}
+}
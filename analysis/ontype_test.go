@@ -0,0 +1,42 @@
+package analysis
+
+import (
+	"cc-lsp/lsp"
+	"testing"
+)
+
+func TestOnTypeEdits(t *testing.T) {
+	long := "fix: x\n\nThe list endpoints return pages now, clients that relied on getting every "
+	end := len(long) - len("fix: x\n\n")
+	// 70 characters but 78 bytes
+	german := "fix: x\n\nZahlen für die Übergänge ändern sich, Umlaute wie äöü werden gezählt. "
+	cases := []struct {
+		document string
+		position lsp.Position
+		ch       string
+		expected string
+	}{
+		{"feat:", lsp.Position{Line: 0, Character: 5}, ":", "feat: "},
+		{"Feat(api)!:", lsp.Position{Line: 0, Character: 11}, ":", "feat(api)!: "},
+		{"Feat(", lsp.Position{Line: 0, Character: 5}, "(", "feat("},
+		{"feat: x", lsp.Position{Line: 0, Character: 5}, ":", "feat: x"},
+		{"feat: a:", lsp.Position{Line: 0, Character: 8}, ":", "feat: a:"},
+		{"fix: x\n\nRefs:", lsp.Position{Line: 2, Character: 5}, ":", "fix: x\n\nRefs:"},
+		{"feat: x\n\n# comment", lsp.Position{Line: 1, Character: 0}, "\n", "feat: x\n\n\n# comment"},
+		{"feat: x\n", lsp.Position{Line: 1, Character: 0}, "\n", "feat: x\n\n"},
+		{"feat: x\n\n\nbody", lsp.Position{Line: 1, Character: 0}, "\n", "feat: x\n\n\nbody"},
+		{"feat: \nx", lsp.Position{Line: 1, Character: 0}, "\n", "feat: \nx"},
+		{"feat: x\n\nbody\n", lsp.Position{Line: 3, Character: 0}, "\n", "feat: x\n\nbody\n"},
+		{long, lsp.Position{Line: 2, Character: end}, " ", "fix: x\n\nThe list endpoints return pages now, clients that relied on getting\nevery "},
+		{"fix: x\n\n```\n" + long[8:], lsp.Position{Line: 3, Character: end}, " ", "fix: x\n\n```\n" + long[8:]},
+		{"fix: x\n\nshort ", lsp.Position{Line: 2, Character: 6}, " ", "fix: x\n\nshort "},
+		{german, lsp.Position{Line: 2, Character: len(german) - len("fix: x\n\n")}, " ", german},
+	}
+
+	for idx, tc := range cases {
+		edits := onTypeEdits(tc.document, tc.position, tc.ch, 72)
+		if actual := applyEdits(tc.document, edits); actual != tc.expected {
+			t.Fatalf("Test case %d failed.\nGot %q\nExp %q", idx, actual, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/lsp"
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// the kinds of completion items that can be resolved
+const (
+	typeItem     = "type"
+	breakingItem = "breaking"
+	scopeItem    = "scope"
+	trailerItem  = "trailer"
+	configItem   = "config"
+)
+
+// completionData is sent with a completion item and comes back on resolve
+type completionData struct {
+	Kind string `json:"kind"`
+	Name string `json:"name,omitempty"`
+	// URI is the document the item was offered for
+	URI string `json:"uri,omitempty"`
+}
+
+func (d completionData) encode() json.RawMessage {
+	content, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	return content
+}
+
+// completionMarkup returns the markdown content if the client can render it
+// in the documentation of completion items
+func (s *State) completionMarkup(markdown, plain string) *lsp.MarkupContent {
+	formats := s.Capabilities.TextDocument.Completion.CompletionItem.DocumentationFormat
+	if slices.Contains(formats, lsp.Markdown) {
+		return &lsp.MarkupContent{Kind: lsp.Markdown, Value: markdown}
+	}
+	return &lsp.MarkupContent{Kind: lsp.PlainText, Value: plain}
+}
+
+// ResolveCompletion adds the documentation to a completion item the client
+// is about to show
+func (s *State) ResolveCompletion(id int, item lsp.CompletionItem) lsp.CompletionResolveResponse {
+	var data completionData
+	if item.Data != nil && json.Unmarshal(item.Data, &data) == nil {
+		switch data.Kind {
+		case typeItem:
+			if doc, ok := lsp.TypeDocs[data.Name]; ok {
+				item.Documentation = s.completionMarkup(doc.Markdown(data.Name), doc.PlainText(data.Name))
+			}
+		case breakingItem:
+			item.Documentation = s.completionMarkup(lsp.BreakingDoc.Markdown("!"), lsp.BreakingDoc.PlainText("!"))
+		case trailerItem:
+			for _, trailer := range lsp.Trailers {
+				if trailer.Key == data.Name {
+					item.Documentation = s.completionMarkup("**"+trailer.Key+"**: "+trailer.Documentation, trailer.Key+": "+trailer.Documentation)
+				}
+			}
+		case scopeItem:
+			item.Documentation = s.scopeDocumentation(data.URI, data.Name)
+		case configItem:
+			if doc, ok := config.Documentation(data.Name); ok {
+				item.Documentation = s.completionMarkup("**"+data.Name+"**\n\n"+doc, data.Name+": "+doc)
+			}
+		}
+	}
+
+	return lsp.CompletionResolveResponse{
+		Response: lsp.Response{
+			RPC: "2.0",
+			ID:  &id,
+		},
+		Result: item,
+	}
+}
+
+// scopeDocumentation lists the latest commit subjects that used the scope
+func (s *State) scopeDocumentation(uri, scope string) *lsp.MarkupContent {
+	repo, err := repoForURI(uri)
+	if err != nil {
+		return nil
+	}
+	cfg, err := s.config(repo)
+	if err != nil || !cfg.Scopes.HasProvider(config.HistoryProvider) {
+		return nil
+	}
+	history, err := s.history(repo)
+	if err != nil {
+		return nil
+	}
+	stat, ok := history.Scopes[scope]
+	if !ok || len(stat.Recent) == 0 {
+		return nil
+	}
+
+	markdown := "**" + scope + "**: recent commits\n\n```gitcommit\n" + strings.Join(stat.Recent, "\n") + "\n```"
+	plain := scope + ": recent commits\n\n" + strings.Join(stat.Recent, "\n")
+	return s.completionMarkup(markdown, plain)
+}
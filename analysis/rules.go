@@ -0,0 +1,172 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/conventional"
+	"cc-lsp/git"
+	"cc-lsp/lsp"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// severity maps the level of a rule to the severity of its diagnostics
+func severity(level config.Level) int {
+	const (
+		errorSeverity   = 1
+		warningSeverity = 2
+	)
+	if level == config.Error {
+		return errorSeverity
+	}
+	return warningSeverity
+}
+
+// ruleDiagnostics checks the commit of the document against the rules in the config
+func (s *State) ruleDiagnostics(uri string, repo git.Repo, cfg config.Config, commit conventional.Commit) []lsp.Diagnostic {
+	diagnostics := []lsp.Diagnostic{}
+	if rule, ok := cfg.Rule(config.ScopeEnum); ok {
+		diagnostics = append(diagnostics, s.checkScopeEnum(repo, cfg, rule, commit)...)
+	}
+	if rule, ok := cfg.Rule(config.TypeStagedConsistency); ok {
+		diagnostics = append(diagnostics, checkTypeStaged(repo, rule, commit, s.staged[uri])...)
+	}
+	if rule, ok := cfg.Rule(config.GoAPIBreaking); ok {
+		diagnostics = append(diagnostics, checkGoAPIBreaking(repo, rule, commit, s.apiChanges[uri])...)
+	}
+	if rule, ok := cfg.Rule(config.HeaderMaxLength); ok {
+		diagnostics = append(diagnostics, checkHeaderMaxLength(rule, commit, s.Documents[uri])...)
+	}
+	return diagnostics
+}
+
+// headerLine returns the text of the header and its length in characters
+func headerLine(document string, header *conventional.Header) (string, int) {
+	line := strings.TrimRight(strings.Split(document, "\n")[header.Line], "\r")
+	return line, utf8.RuneCountInString(line)
+}
+
+// checkHeaderMaxLength reports the part of the header that is over the limit
+func checkHeaderMaxLength(rule config.Rule, commit conventional.Commit, document string) []lsp.Diagnostic {
+	limit, err := rule.Int(config.DefaultHeaderMaxLength)
+	if commit.Header == nil || err != nil {
+		return nil
+	}
+	line, length := headerLine(document, commit.Header)
+	if length <= limit {
+		return nil
+	}
+
+	// the range starts at the first character over the limit
+	start := 0
+	for range limit {
+		_, size := utf8.DecodeRuneInString(line[start:])
+		start += size
+	}
+	return []lsp.Diagnostic{{
+		Range:    LineRange(commit.Header.Line, start, len(line)),
+		Severity: severity(rule.Level),
+		Source:   "cc-lint",
+		Message:  fmt.Sprintf("Header is %d characters long, the limit is %d (%s)", length, limit, config.HeaderMaxLength),
+	}}
+}
+
+// checkScopeEnum reports scopes that are not in the list of the rule. With
+// always the scopes of the project layout and of the packages are allowed as
+// well, so new packages are valid before anyone committed to them. The
+// history is not, it holds every scope that was ever used.
+func (s *State) checkScopeEnum(repo git.Repo, cfg config.Config, rule config.Rule, commit conventional.Commit) []lsp.Diagnostic {
+	if !commit.Conventional || commit.Scope == "" {
+		return nil
+	}
+	values, err := rule.Strings()
+	if err != nil {
+		return nil
+	}
+
+	message := ""
+	if rule.Applicable == "never" {
+		if slices.Contains(values, commit.Scope) {
+			message = fmt.Sprintf("Scope %q must not be used", commit.Scope)
+		}
+	} else {
+		allowed := slices.Contains(values, commit.Scope) ||
+			slices.Contains(s.layout(repo, cfg.Scopes), commit.Scope) ||
+			slices.Contains(cfg.PackageScopes(), commit.Scope)
+		if !allowed {
+			message = fmt.Sprintf("Scope %q is not one of the known scopes", commit.Scope)
+		}
+	}
+	if message == "" {
+		return nil
+	}
+
+	return []lsp.Diagnostic{{
+		Range:    commit.Header.Scope,
+		Severity: severity(rule.Level),
+		Source:   "cc-lint",
+		Message:  message + " (" + config.ScopeEnum + ")",
+	}}
+}
+
+// checkTypeStaged warns if the type of the commit contradicts the staged
+// files, the offending files are listed as related information
+func checkTypeStaged(repo git.Repo, rule config.Rule, commit conventional.Commit, staged []string) []lsp.Diagnostic {
+	// nothing staged happens for amends, there is nothing to compare with
+	if !commit.Conventional || len(staged) == 0 {
+		return nil
+	}
+	message, offending := typeContradictions(commit.Type, staged)
+	if len(offending) == 0 {
+		return nil
+	}
+
+	related := []lsp.DiagnosticRelatedInformation{}
+	for _, file := range offending {
+		related = append(related, lsp.DiagnosticRelatedInformation{
+			Location: lsp.Location{
+				URI:   pathToURI(filepath.Join(repo.Dir, filepath.FromSlash(file))),
+				Range: LineRange(0, 0, 0),
+			},
+			Message: "staged: " + file,
+		})
+	}
+	return []lsp.Diagnostic{{
+		Range:              commit.Header.Type,
+		Severity:           severity(rule.Level),
+		Source:             "cc-lint",
+		Message:            message + " (" + config.TypeStagedConsistency + ")",
+		RelatedInformation: related,
+	}}
+}
+
+// checkGoAPIBreaking reports changes of the exported Go API in a commit that
+// is not marked as a breaking change, the code action adds the marker
+func checkGoAPIBreaking(repo git.Repo, rule config.Rule, commit conventional.Commit, changes []apiChange) []lsp.Diagnostic {
+	if !commit.Conventional || commit.Breaking || len(changes) == 0 {
+		return nil
+	}
+
+	names := []string{}
+	related := []lsp.DiagnosticRelatedInformation{}
+	for _, change := range changes {
+		names = append(names, change.Name)
+		related = append(related, lsp.DiagnosticRelatedInformation{
+			Location: lsp.Location{
+				URI:   pathToURI(filepath.Join(repo.Dir, filepath.FromSlash(change.file))),
+				Range: LineRange(0, 0, 0),
+			},
+			Message: change.String(),
+		})
+	}
+	return []lsp.Diagnostic{{
+		Range:              commit.Header.Type,
+		Severity:           severity(rule.Level),
+		Source:             "cc-lint",
+		Code:               config.GoAPIBreaking,
+		Message:            "The staged changes break the exported Go API (" + strings.Join(names, ", ") + "), mark the commit with ! or a BREAKING CHANGE footer (" + config.GoAPIBreaking + ")",
+		RelatedInformation: related,
+	}}
+}
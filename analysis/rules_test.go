@@ -0,0 +1,51 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScopeEnum(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) {
+		options := []string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}
+		if out, err := exec.Command("git", append(options, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %s", args[0], out)
+		}
+	}
+	git("init", "--quiet")
+	git("commit", "--quiet", "--allow-empty", "-m", "feat(legacy): scope of the history")
+	content := `{"rules": {"scope-enum": [2, "always", ["api"]]}, "packages": [{"name": "billing", "path": "billing"}]}`
+	if err := os.WriteFile(filepath.Join(dir, config.File), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	uri := "file://" + filepath.ToSlash(dir) + "/.git/COMMIT_EDITMSG"
+
+	cases := []struct {
+		message string
+		allowed bool
+	}{
+		{"feat(api): x", true},
+		{"feat(billing): x", true},
+		{"feat: x", true},
+		{"feat(legacy): x", false},
+		{"feat(other): x", false},
+	}
+
+	state := NewState()
+	for idx, tc := range cases {
+		allowed := true
+		for _, diagnostic := range state.OpenDocument(uri, tc.message) {
+			if strings.HasSuffix(diagnostic.Message, "("+config.ScopeEnum+")") {
+				allowed = false
+			}
+		}
+		if allowed != tc.allowed {
+			t.Fatalf("Test case %d failed. Got %t - Exp %t", idx, allowed, tc.allowed)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/git"
+	"cc-lsp/scopes"
+	"time"
+)
+
+// layoutTTL is how long the scopes found in the layout of a repository are
+// reused before the files are scanned again
+const layoutTTL = 30 * time.Second
+
+type layoutScopes struct {
+	scopes  []string
+	scanned time.Time
+}
+
+// knownScope is a scope and where it comes from
+type knownScope struct {
+	name   string
+	detail string
+}
+
+// history returns the scope index of the repository, it is loaded once and
+// brought up to date with HEAD and cached again on every call
+func (s *State) history(repo git.Repo) (*scopes.History, error) {
+	history, ok := s.histories[repo.Dir]
+	if !ok {
+		history, err := scopes.LoadHistory(repo)
+		if err != nil {
+			return nil, err
+		}
+		s.histories[repo.Dir] = history
+		return history, nil
+	}
+	return history, history.Refresh(repo)
+}
+
+// layout returns the scopes found in the files of the repository
+func (s *State) layout(repo git.Repo, cfg config.ScopeConfig) []string {
+	cached, ok := s.layouts[repo.Dir]
+	if ok && time.Since(cached.scanned) < layoutTTL {
+		return cached.scopes
+	}
+	found := scopes.Layout(repo.Dir, cfg)
+	s.layouts[repo.Dir] = layoutScopes{scopes: found, scanned: time.Now()}
+	return found
+}
+
+// knownScopes returns the scopes of the enabled providers: the history
+// ranked by frequency and recency, then the project layout and the packages
+// of the config
+func (s *State) knownScopes(repo git.Repo, cfg config.Config) []knownScope {
+	known := []knownScope{}
+	seen := map[string]bool{}
+	add := func(name, detail string) {
+		if !seen[name] {
+			seen[name] = true
+			known = append(known, knownScope{name: name, detail: detail})
+		}
+	}
+
+	if cfg.Scopes.HasProvider(config.HistoryProvider) {
+		if history, err := s.history(repo); err == nil {
+			now := time.Now()
+			for _, stat := range history.Ranked(now) {
+				add(stat.Name, stat.Describe(now))
+			}
+		}
+	}
+	for _, scope := range s.layout(repo, cfg.Scopes) {
+		add(scope, "found in the project layout")
+	}
+	for _, scope := range cfg.PackageScopes() {
+		add(scope, "package scope")
+	}
+	return known
+}
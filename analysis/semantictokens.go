@@ -0,0 +1,163 @@
+package analysis
+
+import (
+	"cc-lsp/conventional"
+	"cc-lsp/lsp"
+	"slices"
+	"strings"
+)
+
+// semanticToken is a token on a single line, end is exclusive
+type semanticToken struct {
+	line, start, end int
+	typ, modifiers   int
+}
+
+// semanticTokens returns the tokens of the parsed message sorted by position
+func semanticTokens(document string) []semanticToken {
+	commit := conventional.Parse(document)
+	tokens := []semanticToken{}
+	add := func(r lsp.Range, typ, modifiers int) {
+		if r.End.Character > r.Start.Character {
+			tokens = append(tokens, semanticToken{r.Start.Line, r.Start.Character, r.End.Character, typ, modifiers})
+		}
+	}
+
+	if header := commit.Header; header != nil {
+		modifiers := 0
+		if header.UnknownType {
+			modifiers = lsp.UnknownModifier
+		}
+		add(header.Type, lsp.CommitTypeToken, modifiers)
+		add(header.Scope, lsp.ScopeToken, 0)
+		add(header.Breaking, lsp.BreakingMarkerToken, 0)
+		add(header.Description, lsp.DescriptionToken, 0)
+	}
+	for _, footer := range commit.Footers {
+		add(footer.TokenRange, lsp.FooterTokenToken, 0)
+		for _, value := range footer.ValueRanges {
+			add(value, lsp.FooterValueToken, 0)
+		}
+	}
+
+	lines := strings.Split(document, "\n")
+	comments := commit.Comments
+	if commit.Scissors >= 0 {
+		comments = append(comments, commit.Scissors)
+	}
+	for _, number := range comments {
+		add(LineRange(number, 0, len(strings.TrimRight(lines[number], "\r"))), lsp.CommentToken, 0)
+	}
+
+	// issue references cut the token they are in into pieces
+	for _, reference := range commit.References {
+		line, start, end := reference.Start.Line, reference.Start.Character, reference.End.Character
+		split := []semanticToken{}
+		for _, token := range tokens {
+			if token.line != line || token.end <= start || token.start >= end {
+				split = append(split, token)
+				continue
+			}
+			if token.start < start {
+				split = append(split, semanticToken{line, token.start, start, token.typ, token.modifiers})
+			}
+			if token.end > end {
+				split = append(split, semanticToken{line, end, token.end, token.typ, token.modifiers})
+			}
+		}
+		tokens = append(split, semanticToken{line, start, end, lsp.IssueReferenceToken, 0})
+	}
+
+	slices.SortFunc(tokens, func(a, b semanticToken) int {
+		if a.line != b.line {
+			return a.line - b.line
+		}
+		return a.start - b.start
+	})
+	return tokens
+}
+
+// PositionEncoding returns the encoding of the characters of positions, the
+// byte offsets of UTF-8 if the client supports them and UTF-16 otherwise
+func (s *State) PositionEncoding() string {
+	if slices.Contains(s.Capabilities.General.PositionEncodings, lsp.UTF8Encoding) {
+		return lsp.UTF8Encoding
+	}
+	return lsp.UTF16Encoding
+}
+
+// documentTokens returns the tokens of the document, their characters are
+// converted to UTF-16 unless the client counts in UTF-8
+func (s *State) documentTokens(document string) []semanticToken {
+	tokens := semanticTokens(document)
+	if s.PositionEncoding() == lsp.UTF8Encoding {
+		return tokens
+	}
+	lines := strings.Split(document, "\n")
+	for idx, token := range tokens {
+		line := lines[token.line]
+		tokens[idx].start, tokens[idx].end = utf16Column(line, token.start), utf16Column(line, token.end)
+	}
+	return tokens
+}
+
+// utf16Column counts the UTF-16 code units in front of the byte offset
+func utf16Column(line string, offset int) int {
+	column := 0
+	for _, r := range line[:min(offset, len(line))] {
+		column++
+		if r >= 0x10000 {
+			// a surrogate pair
+			column++
+		}
+	}
+	return column
+}
+
+// encodeSemanticTokens encodes the sorted tokens relative to each other
+func encodeSemanticTokens(tokens []semanticToken) []int {
+	data := []int{}
+	line, start := 0, 0
+	for _, token := range tokens {
+		if token.line != line {
+			start = 0
+		}
+		data = append(data, token.line-line, token.start-start, token.end-token.start, token.typ, token.modifiers)
+		line, start = token.line, token.start
+	}
+	return data
+}
+
+func (s *State) SemanticTokens(id int, uri string) lsp.SemanticTokensResponse {
+	return lsp.SemanticTokensResponse{
+		Response: lsp.Response{
+			RPC: "2.0",
+			ID:  &id,
+		},
+		Result: lsp.SemanticTokens{Data: encodeSemanticTokens(s.documentTokens(s.Documents[uri]))},
+	}
+}
+
+// SemanticTokensRange only returns the tokens that overlap the range
+func (s *State) SemanticTokensRange(id int, uri string, r lsp.Range) lsp.SemanticTokensResponse {
+	tokens := []semanticToken{}
+	for _, token := range s.documentTokens(s.Documents[uri]) {
+		if token.line < r.Start.Line || token.line > r.End.Line {
+			continue
+		}
+		if token.line == r.Start.Line && token.end <= r.Start.Character {
+			continue
+		}
+		if token.line == r.End.Line && token.start >= r.End.Character {
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+	return lsp.SemanticTokensResponse{
+		Response: lsp.Response{
+			RPC: "2.0",
+			ID:  &id,
+		},
+		Result: lsp.SemanticTokens{Data: encodeSemanticTokens(tokens)},
+	}
+}
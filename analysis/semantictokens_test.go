@@ -0,0 +1,52 @@
+package analysis
+
+import (
+	"cc-lsp/lsp"
+	"reflect"
+	"testing"
+)
+
+func TestSemanticTokens(t *testing.T) {
+	document := "feat(api)!: add paging, see #12\n\nRefs: #12\n# comment"
+	expected := []int{
+		0, 0, 4, lsp.CommitTypeToken, 0,
+		0, 5, 3, lsp.ScopeToken, 0,
+		0, 4, 1, lsp.BreakingMarkerToken, 0,
+		0, 3, 16, lsp.DescriptionToken, 0,
+		0, 16, 3, lsp.IssueReferenceToken, 0,
+		2, 0, 4, lsp.FooterTokenToken, 0,
+		0, 6, 3, lsp.IssueReferenceToken, 0,
+		1, 0, 9, lsp.CommentToken, 0,
+	}
+	if actual := encodeSemanticTokens(semanticTokens(document)); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Got %v - Exp %v", actual, expected)
+	}
+
+	unknown := semanticTokens("chore: x")
+	if len(unknown) != 2 || unknown[0].modifiers != lsp.UnknownModifier {
+		t.Fatalf("unknown types should have the unknown modifier %+v", unknown)
+	}
+
+	state := NewState()
+	state.Documents["uri"] = document
+	response := state.SemanticTokensRange(1, "uri", lsp.Range{Start: lsp.Position{Line: 2}, End: lsp.Position{Line: 3}})
+	if expected := []int{2, 0, 4, lsp.FooterTokenToken, 0, 0, 6, 3, lsp.IssueReferenceToken, 0}; !reflect.DeepEqual(response.Result.Data, expected) {
+		t.Fatalf("Got %v - Exp %v", response.Result.Data, expected)
+	}
+
+	// ü is two bytes but one UTF-16 unit, 😀 is four bytes and two units
+	state.Documents["uri"] = "fix: für 😀 #12"
+	cases := []struct {
+		encodings []string
+		expected  []int
+	}{
+		{nil, []int{0, 0, 3, lsp.CommitTypeToken, 0, 0, 5, 7, lsp.DescriptionToken, 0, 0, 7, 3, lsp.IssueReferenceToken, 0}},
+		{[]string{lsp.UTF16Encoding, lsp.UTF8Encoding}, []int{0, 0, 3, lsp.CommitTypeToken, 0, 0, 5, 10, lsp.DescriptionToken, 0, 0, 10, 3, lsp.IssueReferenceToken, 0}},
+	}
+	for idx, tc := range cases {
+		state.Capabilities.General.PositionEncodings = tc.encodings
+		if actual := state.SemanticTokens(1, "uri").Result.Data; !reflect.DeepEqual(actual, tc.expected) {
+			t.Fatalf("Test case %d failed. Got %v - Exp %v", idx, actual, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/git"
+	"cc-lsp/lsp"
+	"encoding/json"
+	"errors"
+)
+
+// InitializationOptions sets the settings the editor sends on initialize
+func (s *State) InitializationOptions(content json.RawMessage) error {
+	settings, err := config.ParseSettings(content)
+	if err != nil {
+		return err
+	}
+	s.initSettings = settings
+	return nil
+}
+
+// ChangeSettings sets the cc-lsp section of the workspace configuration and
+// returns the diagnostics of every document linted with it. Invalid settings
+// keep the previous ones.
+func (s *State) ChangeSettings(content json.RawMessage) (map[string][]lsp.Diagnostic, error) {
+	settings, err := config.ParseSettings(content)
+	if err != nil {
+		return nil, err
+	}
+	s.workspaceSettings = settings
+	// the settings may select other scope providers
+	clear(s.layouts)
+	return s.lintAll(), nil
+}
+
+// ConfigFilesChanged validates the config of the repositories the changed,
+// created or deleted config files are in and returns the diagnostics of
+// every document. The config that is loaded is validated, not the file that
+// changed: it may be ignored for one that comes first, or deleting it may
+// put another one in charge. The diagnostics are returned for an invalid
+// config too, the rules of the repository are skipped then.
+func (s *State) ConfigFilesChanged(uris []string) (map[string][]lsp.Diagnostic, error) {
+	clear(s.layouts)
+	errs := []error{}
+	validated := map[string]bool{}
+	for _, uri := range uris {
+		// config files outside of a repository are never read
+		repo, err := repoForURI(uri)
+		if err != nil || validated[repo.Dir] {
+			continue
+		}
+		validated[repo.Dir] = true
+		if _, err := config.Load(repo.Dir); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return s.lintAll(), errors.Join(errs...)
+}
+
+// settings returns the settings of the editor, the workspace configuration
+// overrides the initializationOptions
+func (s *State) settings() config.Config {
+	return s.initSettings.Merge(s.workspaceSettings)
+}
+
+// config returns the config of the repository with the settings of the
+// editor on top
+func (s *State) config(repo git.Repo) (config.Config, error) {
+	cfg, err := config.Load(repo.Dir)
+	if err != nil {
+		return config.Config{}, err
+	}
+	return cfg.Merge(s.settings()), nil
+}
+
+// lintAll reads the staged changes again, the rules that look at them may
+// have been switched, and returns the diagnostics of every document
+func (s *State) lintAll() map[string][]lsp.Diagnostic {
+	diagnostics := map[string][]lsp.Diagnostic{}
+	for uri, text := range s.Documents {
+		s.readStaged(uri)
+		diagnostics[uri] = s.diagnostics(uri, text)
+	}
+	return diagnostics
+}
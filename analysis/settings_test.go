@@ -0,0 +1,102 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/lsp"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChangeSettings(t *testing.T) {
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "--quiet", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %s", out)
+	}
+	uri := "file://" + filepath.ToSlash(dir) + "/.git/COMMIT_EDITMSG"
+	tooLong := func(diagnostics []lsp.Diagnostic) bool {
+		for _, diagnostic := range diagnostics {
+			if strings.HasSuffix(diagnostic.Message, "(header-max-length)") {
+				return true
+			}
+		}
+		return false
+	}
+
+	state := NewState()
+	if err := state.InitializationOptions([]byte(`{"rules": {"header-max-length": [2, "always", 10]}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if !tooLong(state.OpenDocument(uri, "fix: a header of 26 chars")) {
+		t.Fatal("the initializationOptions should lower the limit")
+	}
+
+	diagnostics, err := state.ChangeSettings([]byte(`{"preset": "minimal", "rules": {"header-max-length": [2, "always", 50]}}`))
+	if err != nil || tooLong(diagnostics[uri]) {
+		t.Fatalf("the workspace configuration should override the initializationOptions, got %+v %v", diagnostics, err)
+	}
+	if _, err := state.ChangeSettings([]byte(`{"rules": {"header-max-length": [5]}}`)); err == nil {
+		t.Fatal("invalid settings should be rejected")
+	}
+	if tooLong(state.diagnostics(uri, state.Documents[uri])) {
+		t.Fatal("invalid settings should keep the previous ones")
+	}
+
+	state.ChangeSettings([]byte(`{"format": {"wrapColumn": 100}}`))
+	if column := state.wrapColumn("file:///does/not/exist/COMMIT_EDITMSG"); column != 100 {
+		t.Fatalf("the settings should apply outside of a repository, got %d", column)
+	}
+}
+
+func TestConfigFilesChanged(t *testing.T) {
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "--quiet", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %s", out)
+	}
+	uri := "file://" + filepath.ToSlash(dir) + "/.git/COMMIT_EDITMSG"
+	configURI := func(name string) string {
+		return "file://" + filepath.ToSlash(dir) + "/" + name
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	state := NewState()
+	state.OpenDocument(uri, "fix: a header of 26 chars")
+	write(config.YAMLFile, "rules:\n  header-max-length: [2, always, 10]\n")
+	diagnostics, err := state.ConfigFilesChanged([]string{configURI(config.YAMLFile)})
+	if err != nil || len(diagnostics[uri]) != 1 {
+		t.Fatalf("the document should be linted with the new limit, got %+v %v", diagnostics, err)
+	}
+
+	write(config.YAMLFile, "rules:\n  header-max-length: [2, sometimes]\n")
+	diagnostics, err = state.ConfigFilesChanged([]string{configURI(config.YAMLFile)})
+	if err == nil || len(diagnostics[uri]) != 0 {
+		t.Fatalf("an invalid config should be reported and skipped, got %+v %v", diagnostics, err)
+	}
+
+	// the JSON config comes first, the YAML and the commitlint config are
+	// ignored next to it
+	write(config.File, `{"rules": {"header-max-length": [2, "always", 20]}}`)
+	write(config.CommitlintFile, `{"rules": `)
+	changed := []string{configURI(config.File), configURI(config.CommitlintFile)}
+	if diagnostics, err := state.ConfigFilesChanged(changed); err != nil || len(diagnostics[uri]) != 1 {
+		t.Fatalf("the ignored configs should not be reported, got %+v %v", diagnostics, err)
+	}
+
+	// without the JSON config the invalid YAML config is in charge
+	os.Remove(filepath.Join(dir, config.File))
+	if _, err := state.ConfigFilesChanged([]string{configURI(config.File)}); err == nil || !strings.Contains(err.Error(), config.YAMLFile) {
+		t.Fatalf("the YAML config that takes over should be validated, got %v", err)
+	}
+
+	os.Remove(filepath.Join(dir, config.YAMLFile))
+	os.Remove(filepath.Join(dir, config.CommitlintFile))
+	if _, err := state.ConfigFilesChanged([]string{configURI(config.YAMLFile), configURI(config.CommitlintFile)}); err != nil {
+		t.Fatalf("a repository without a config should be valid, got %v", err)
+	}
+}
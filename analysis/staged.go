@@ -0,0 +1,143 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"path"
+	"strings"
+)
+
+// stagedSuggestion is the type and scope the staged changes point to
+type stagedSuggestion struct {
+	// typ is empty if the files do not point to a single type
+	typ string
+	// scope is empty if the files do not share a directory
+	scope string
+}
+
+// typeRules map the staged files to a type if every file matches
+var typeRules = []struct {
+	typ     string
+	matches func(file string) bool
+}{
+	{"test", func(file string) bool { return strings.HasSuffix(file, "_test.go") }},
+	{"docs", func(file string) bool { return strings.HasSuffix(strings.ToLower(file), ".md") }},
+	{"ci", func(file string) bool { return strings.HasPrefix(file, ".github/workflows/") }},
+	{"build", func(file string) bool {
+		return file == "go.mod" || file == "go.sum" || strings.HasSuffix(file, "/go.mod") || strings.HasSuffix(file, "/go.sum")
+	}},
+}
+
+// suggestFromStaged looks at the staged files to suggest the type and the
+// scope of the commit, the package of the config that holds all files wins
+// over their shared directory
+func suggestFromStaged(files []string, cfg config.Config) stagedSuggestion {
+	suggestion := stagedSuggestion{}
+	if len(files) == 0 {
+		return suggestion
+	}
+
+	for _, rule := range typeRules {
+		all := true
+		for _, file := range files {
+			all = all && rule.matches(file)
+		}
+		if all {
+			suggestion.typ = rule.typ
+			break
+		}
+	}
+
+	for _, pkg := range cfg.Packages {
+		all := true
+		for _, file := range files {
+			all = all && pkg.HasFile(file)
+		}
+		if all {
+			suggestion.scope = pkg.Name
+			if len(pkg.Scopes) > 0 {
+				suggestion.scope = pkg.Scopes[0]
+			}
+			return suggestion
+		}
+	}
+
+	shared := sharedDir(files)
+	// directories like .github do not make a good scope
+	if shared != "" && !strings.HasPrefix(shared, ".") {
+		suggestion.scope = path.Base(shared)
+	}
+	return suggestion
+}
+
+// sharedDir returns the deepest directory that holds all files, empty if
+// that is the repository root
+func sharedDir(files []string) string {
+	shared := path.Dir(files[0])
+	for _, file := range files[1:] {
+		for shared != "." && !strings.HasPrefix(file, shared+"/") {
+			shared = path.Dir(shared)
+		}
+	}
+	if shared == "." {
+		return ""
+	}
+	return shared
+}
+
+// sourceExtensions are the extensions of files that hold code
+var sourceExtensions = map[string]bool{
+	".go": true, ".js": true, ".jsx": true, ".ts": true, ".tsx": true, ".py": true, ".rs": true,
+	".java": true, ".kt": true, ".c": true, ".h": true, ".cpp": true, ".cs": true, ".rb": true,
+	".php": true, ".swift": true, ".lua": true, ".sh": true,
+}
+
+func isSourceFile(file string) bool {
+	return sourceExtensions[path.Ext(file)]
+}
+
+func isTestFile(file string) bool {
+	base := path.Base(file)
+	for _, dir := range strings.Split(path.Dir(file), "/") {
+		if dir == "test" || dir == "tests" || dir == "testdata" || dir == "__tests__" {
+			return true
+		}
+	}
+	return strings.HasSuffix(base, "_test.go") || strings.HasPrefix(base, "test_") ||
+		strings.HasSuffix(strings.TrimSuffix(base, path.Ext(base)), "_test") ||
+		strings.Contains(base, ".test.") || strings.Contains(base, ".spec.")
+}
+
+func isCIFile(file string) bool {
+	return strings.HasPrefix(file, ".github/workflows/") || strings.HasPrefix(file, ".circleci/") ||
+		file == ".gitlab-ci.yml" || file == ".travis.yml" || file == "Jenkinsfile"
+}
+
+// typeContradictions returns why the staged files contradict the type and
+// the files that do
+func typeContradictions(typ string, files []string) (string, []string) {
+	offending := []string{}
+	switch typ {
+	case "docs":
+		for _, file := range files {
+			if isSourceFile(file) {
+				offending = append(offending, file)
+			}
+		}
+		return "docs commits should only change documentation, but source files are staged", offending
+	case "test":
+		for _, file := range files {
+			if isTestFile(file) {
+				return "", nil
+			}
+		}
+		return "test commits should change tests, but no test files are staged", files
+	case "ci":
+		for _, file := range files {
+			if isCIFile(file) {
+				return "", nil
+			}
+		}
+		return "ci commits should change the CI configuration, but no CI files are staged", files
+	}
+	return "", nil
+}
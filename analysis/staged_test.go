@@ -0,0 +1,86 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/conventional"
+	"cc-lsp/git"
+	"reflect"
+	"testing"
+)
+
+func TestSuggestFromStaged(t *testing.T) {
+	cfg := config.Config{Packages: []config.Package{
+		{Name: "billing", Path: "services/billing"},
+		{Name: "auth", Path: "services/auth", Scopes: []string{"login"}},
+	}}
+	cases := []struct {
+		files    []string
+		expected stagedSuggestion
+	}{
+		{nil, stagedSuggestion{}},
+		{[]string{"analysis/state_test.go", "rpc/rpc_test.go"}, stagedSuggestion{typ: "test"}},
+		{[]string{"analysis/state_test.go", "analysis/state.go"}, stagedSuggestion{scope: "analysis"}},
+		{[]string{"readme.md", "docs/guide.MD"}, stagedSuggestion{typ: "docs"}},
+		{[]string{".github/workflows/ci.yml", ".github/workflows/release.yml"}, stagedSuggestion{typ: "ci"}},
+		{[]string{"go.mod", "go.sum"}, stagedSuggestion{typ: "build"}},
+		{[]string{"tools/go.mod", "tools/go.sum"}, stagedSuggestion{typ: "build", scope: "tools"}},
+		{[]string{"services/billing/invoice.go", "services/billing/pdf/render.go"}, stagedSuggestion{scope: "billing"}},
+		{[]string{"services/auth/readme.md"}, stagedSuggestion{typ: "docs", scope: "login"}},
+		{[]string{"services/billing/invoice.go", "services/auth/login.go"}, stagedSuggestion{scope: "services"}},
+		{[]string{"lsp/pkg/a.go", "lsp/pkg/b.go", "lsp/c.go"}, stagedSuggestion{scope: "lsp"}},
+		{[]string{"main.go", "lsp/c.go"}, stagedSuggestion{}},
+	}
+
+	for idx, tc := range cases {
+		if actual := suggestFromStaged(tc.files, cfg); actual != tc.expected {
+			t.Fatalf("Test case %d failed. Got %+v - Exp %+v", idx, actual, tc.expected)
+		}
+	}
+}
+
+func TestTypeContradictions(t *testing.T) {
+	cases := []struct {
+		typ       string
+		files     []string
+		offending []string
+	}{
+		{"docs", []string{"readme.md", "docs/guide.md"}, nil},
+		{"docs", []string{"readme.md", "analysis/state.go", "web/app.ts"}, []string{"analysis/state.go", "web/app.ts"}},
+		{"test", []string{"analysis/state_test.go", "analysis/state.go"}, nil},
+		{"test", []string{"web/app.spec.ts"}, nil},
+		{"test", []string{"tests/helpers.py"}, nil},
+		{"test", []string{"analysis/state.go", "readme.md"}, []string{"analysis/state.go", "readme.md"}},
+		{"ci", []string{".github/workflows/ci.yml", "Makefile"}, nil},
+		{"ci", []string{"Makefile"}, []string{"Makefile"}},
+		{"feat", []string{"readme.md"}, nil},
+	}
+
+	for idx, tc := range cases {
+		_, offending := typeContradictions(tc.typ, tc.files)
+		if !reflect.DeepEqual(offending, tc.offending) && (len(offending) != 0 || len(tc.offending) != 0) {
+			t.Fatalf("Test case %d failed. Got %v - Exp %v", idx, offending, tc.offending)
+		}
+	}
+}
+
+func TestCheckTypeStaged(t *testing.T) {
+	repo := git.Repo{Dir: "/repo"}
+	rule := config.DefaultRules[config.TypeStagedConsistency]
+	commit := conventional.Parse("docs(api): explain pagination")
+
+	diagnostics := checkTypeStaged(repo, rule, commit, []string{"api/list.go", "api/readme.md"})
+	if len(diagnostics) != 1 {
+		t.Fatalf("docs with staged go files should be reported, got %+v", diagnostics)
+	}
+	diagnostic := diagnostics[0]
+	if diagnostic.Range != LineRange(0, 0, 4) || diagnostic.Severity != 2 {
+		t.Fatalf("the warning should be on the type, got %+v", diagnostic)
+	}
+	if len(diagnostic.RelatedInformation) != 1 || diagnostic.RelatedInformation[0].Location.URI != "file:///repo/api/list.go" {
+		t.Fatalf("the go file should be related, got %+v", diagnostic.RelatedInformation)
+	}
+
+	if diagnostics := checkTypeStaged(repo, rule, commit, nil); len(diagnostics) != 0 {
+		t.Fatalf("without staged files nothing should be reported, got %+v", diagnostics)
+	}
+}
@@ -1,8 +1,11 @@
 package analysis
 
 import (
+	"cc-lsp/config"
+	"cc-lsp/conventional"
 	"cc-lsp/lsp"
-	"regexp"
+	"cc-lsp/scopes"
+	"slices"
 	"strings"
 	"unicode"
 )
@@ -10,28 +13,45 @@ import (
 type State struct {
 	// Map of file names to contents
 	Documents map[string]string
+	// Capabilities of the client, set on initialize
+	Capabilities lsp.ClientCapabilities
+	// Map of repository directories to the scopes used in their history
+	histories map[string]*scopes.History
+	// Map of repository directories to the scopes found in their layout
+	layouts map[string]layoutScopes
+	// Map of file names to the files that were staged when they were opened
+	staged map[string][]string
+	// Map of file names to the exported Go API the staged files change
+	apiChanges map[string][]apiChange
+	// Map of file names to the message written before they were opened
+	drafts map[string]string
+	// Settings of the editor from the initializationOptions and the
+	// workspace configuration, they override the config of the repository
+	initSettings      config.Config
+	workspaceSettings config.Config
+	// Map of file names to the open config files of cc-lsp, they are not
+	// commit messages and are kept apart from the Documents
+	configDocuments map[string]configDocument
 }
 
 func NewState() State {
-	return State{Documents: map[string]string{}}
+	return State{
+		Documents:  map[string]string{},
+		histories:  map[string]*scopes.History{},
+		layouts:    map[string]layoutScopes{},
+		staged:     map[string][]string{},
+		apiChanges: map[string][]apiChange{},
+		drafts:     map[string]string{},
+
+		configDocuments: map[string]configDocument{},
+	}
 }
 
 // diagnoseNoConventionalCommitMsg evaluates the first line that has text in
 // the git commit and returns an diagnose if the line does not match a
 // conventional commit format
 func diagnoseNoConventionalCommitMsg(text string) (lsp.Diagnostic, bool) {
-	// todo: expand the list to the angular conventional commits
-	middleRegex := ""
-	for _, item := range lsp.Prefixes {
-		middleRegex += regexp.QuoteMeta(item) + "|"
-	}
-
-	// Build the regex pattern dynamically based on the list of prefixes
-	pattern := `^(?:` + middleRegex + `)(?:\(.+\))?!?:\s+`
-
-	// Compile the regex
-	re := regexp.MustCompile(pattern)
-	if re.FindStringIndex(text) == nil {
+	if !conventional.Parse(text).Conventional {
 		diagnostic := lsp.Diagnostic{
 			Range:    LineRange(0, 0, 0),
 			Severity: 1,
@@ -73,93 +93,185 @@ func getDiagnosticsForFile(text string) []lsp.Diagnostic {
 
 func (s *State) OpenDocument(uri, text string) []lsp.Diagnostic {
 	s.Documents[uri] = text
+	s.readStaged(uri)
+	if repo, err := repoForURI(uri); err == nil {
+		s.drafts[uri] = loadDraft(repo)
+	}
 
-	return getDiagnosticsForFile(text)
+	return s.diagnostics(uri, text)
+}
+
+// readStaged reads the staged changes of the document. They are what the
+// commit message describes and do not change while the message is written.
+func (s *State) readStaged(uri string) {
+	repo, err := repoForURI(uri)
+	if err != nil {
+		return
+	}
+	if files, err := repo.StagedFiles(); err == nil {
+		s.staged[uri] = files
+	}
+	if cfg, err := s.config(repo); err == nil {
+		if _, ok := cfg.Rule(config.GoAPIBreaking); ok {
+			s.apiChanges[uri] = stagedAPIChanges(repo, s.staged[uri])
+		} else {
+			delete(s.apiChanges, uri)
+		}
+	}
 }
 
 func (s *State) UpdateDocument(uri, text string) []lsp.Diagnostic {
+	if document, ok := s.configDocuments[uri]; ok {
+		document.text = text
+		s.configDocuments[uri] = document
+		return configDiagnostics(document)
+	}
 	s.Documents[uri] = text
+	return s.diagnostics(uri, text)
+}
 
-	return getDiagnosticsForFile(text)
+// SaveDocument keeps the message of the document as the draft, it is saved
+// and not changed so git runs once and not on every key
+func (s *State) SaveDocument(uri string) error {
+	text, ok := s.Documents[uri]
+	if !ok {
+		return nil
+	}
+	repo, err := repoForURI(uri)
+	if err != nil {
+		return err
+	}
+	return saveDraft(repo, text)
 }
 
-func (s *State) Hover(id int, uri string, position lsp.Position) lsp.HoverResponse {
-	// In real life, this would look up the type in our type analysis code...
+// CloseDocument saves the draft of the document and forgets it
+func (s *State) CloseDocument(uri string) error {
+	err := s.SaveDocument(uri)
+	delete(s.Documents, uri)
+	delete(s.configDocuments, uri)
+	delete(s.drafts, uri)
+	delete(s.staged, uri)
+	delete(s.apiChanges, uri)
+	return err
+}
 
-	document := s.Documents[uri]
-	line := strings.Split(document, "\n")[position.Line]
-	word := getWord(line, position)
-	content, ok := lsp.HoverContents[word]
-	if ok {
-		return lsp.HoverResponse{
-			Response: lsp.Response{
-				RPC: "2.0",
-				ID:  &id,
-			},
-			Result: lsp.HoverResult{
-				Contents: string(content),
-			},
-		}
+// diagnostics adds the diagnostics of the rules configured in the repository
+// that owns the document to the built in ones
+func (s *State) diagnostics(uri, text string) []lsp.Diagnostic {
+	diagnostics := getDiagnosticsForFile(text)
+	repo, err := repoForURI(uri)
+	if err != nil {
+		return diagnostics
+	}
+	cfg, err := s.config(repo)
+	if err != nil {
+		return diagnostics
+	}
+	return append(diagnostics, s.ruleDiagnostics(uri, repo, cfg, conventional.Parse(text))...)
+}
 
+func (s *State) Hover(id int, uri string, position lsp.Position) lsp.HoverResponse {
+	if document, ok := s.configDocuments[uri]; ok {
+		return s.configHover(id, document, position)
 	}
-	// for now return empty hover
-	return lsp.HoverResponse{
+	response := lsp.HoverResponse{
 		Response: lsp.Response{
 			RPC: "2.0",
 			ID:  &id,
 		},
-		Result: lsp.HoverResult{
-			Contents: "No Information",
-		},
 	}
-}
 
-func getWord(line string, position lsp.Position) string {
-	// if line is empty there is no word to find
-	if line == "" {
-		return ""
+	document := s.Documents[uri]
+	lines := strings.Split(document, "\n")
+	if position.Line < 0 || position.Line >= len(lines) {
+		return response
+	}
+	line := lines[position.Line]
+	start, end := wordBounds(line, position.Character)
+	name := line[start:end]
+	doc, ok := lsp.TypeDocs[name]
+
+	// on the type or the breaking change marker show what the commit does to the version
+	impact := ""
+	commit := conventional.Parse(document)
+	if header := commit.Header; commit.Conventional && header.Line == position.Line {
+		onType := conventional.Contains(header.Type, position)
+		onBreaking := conventional.Contains(header.Breaking, position)
+		if onBreaking {
+			doc, ok = lsp.BreakingDoc, true
+		}
+		if onType || onBreaking {
+			impact, _ = s.versionImpact(uri, commit)
+		}
 	}
-	character := position.Character
-	res := string(line[character])
-	// check whether we hit a space
-	if res == " " || !unicode.IsLetter(rune(res[0])) {
-		return res
+	if !ok {
+		// nothing to show for blanks and unknown words
+		return response
 	}
 
-	// check whether we are out of bounds
-	if !(character+1 >= len(line)) {
+	markdown, plain := doc.Markdown(name), doc.PlainText(name)
+	if impact != "" {
+		markdown += "\n\n---\n\n**Version:** " + impact
+		plain += "\n\nVersion: " + impact
+	}
+	wordRange := LineRange(position.Line, start, end)
+	response.Result = &lsp.HoverResult{
+		Contents: s.markup(markdown, plain),
+		Range:    &wordRange,
+	}
+	return response
+}
 
-		// go to the end of the word
-		for unicode.IsLetter(rune(line[character+1])) {
-			character += 1
-			res += string(line[character])
-			// check if we are at the end of the line in the next run
-			if character+1 >= len(line) {
-				break
-			}
-		}
+// markup returns the markdown content if the client can render it
+func (s *State) markup(markdown, plain string) lsp.MarkupContent {
+	if slices.Contains(s.Capabilities.TextDocument.Hover.ContentFormat, lsp.Markdown) {
+		return lsp.MarkupContent{Kind: lsp.Markdown, Value: markdown}
 	}
-	// go the other way reset the character position
-	character = position.Character
-	// check for bound again
-	if !(character-1 < 0) {
-		// go to the start of the word
-		for unicode.IsLetter(rune(line[character-1])) {
-			character -= 1
-			res = string(line[character]) + res
-			// check if we are at the start of the line in the next run
-			if character-1 < 0 {
-				break
-			}
-		}
+	return lsp.MarkupContent{Kind: lsp.PlainText, Value: plain}
+}
+
+// wordBounds returns the start and the end of the word at the character, a
+// character that is not a letter is a word on its own
+func wordBounds(line string, character int) (int, int) {
+	if character < 0 || character >= len(line) {
+		return 0, 0
+	}
+	if !unicode.IsLetter(rune(line[character])) {
+		return character, character + 1
 	}
-	return res
+
+	start, end := character, character+1
+	// go to the start of the word
+	for start > 0 && unicode.IsLetter(rune(line[start-1])) {
+		start--
+	}
+	// go to the end of the word
+	for end < len(line) && unicode.IsLetter(rune(line[end])) {
+		end++
+	}
+	return start, end
 }
 
-func (s *State) TextDocumentCompletion(id int, uri string) lsp.CompletionResponse {
+func (s *State) TextDocumentCompletion(id int, uri string, position lsp.Position) lsp.CompletionResponse {
+	if document, ok := s.configDocuments[uri]; ok {
+		return s.configCompletion(id, document, position)
+	}
+	// offer what fits the part of the message the cursor is in
+	items := []lsp.CompletionItem{}
+	document := s.Documents[uri]
+	context := getCompletionContext(document, position)
+	wordRange, lineEnd := completionRange(document, position, context)
+	switch context {
+	case typeCompletion:
+		// a template would clash with the rest of the header
+		items = s.typeCompletions(uri, lineEnd)
+	case scopeCompletion:
+		items = s.scopeCompletions(uri)
+	case trailerCompletion:
+		items = trailerCompletions()
+	}
+	setTextEdits(items, wordRange)
 
-	// Ask your static analysis tools to figure out good completions
-	items := lsp.GetCompletions()
 	response := lsp.CompletionResponse{
 		Response: lsp.Response{
 			RPC: "2.0",
@@ -2,6 +2,9 @@ package analysis
 
 import (
 	"cc-lsp/lsp"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -110,9 +113,76 @@ func TestGetWord(t *testing.T) {
 	}
 
 	for idx, tc := range cases {
-		word := getWord(tc.line, tc.position)
+		start, end := wordBounds(tc.line, tc.position.Character)
+		word := tc.line[start:end]
 		if word != tc.expected {
 			t.Fatalf("Test case %d failed. Got %s - Exp %s", idx, word, tc.expected)
 		}
 	}
 }
+
+func TestHoverVersionImpact(t *testing.T) {
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--allow-empty", "--message", "feat: init"},
+		{"tag", "v1.4.2"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+
+	uri := "file://" + filepath.ToSlash(dir) + "/.git/COMMIT_EDITMSG"
+	state := NewState()
+	state.OpenDocument(uri, "feat(api)!: add pagination\n# comment")
+
+	cases := []struct {
+		position lsp.Position
+		impact   string
+		word     lsp.Range
+	}{
+		{lsp.Position{Line: 0, Character: 1}, "breaking change -> major bump: 1.4.2 -> 2.0.0", LineRange(0, 0, 4)},
+		{lsp.Position{Line: 0, Character: 9}, "breaking change -> major bump: 1.4.2 -> 2.0.0", LineRange(0, 9, 10)},
+	}
+	for idx, tc := range cases {
+		response := state.Hover(1, uri, tc.position)
+		if response.Result == nil || !strings.HasSuffix(response.Result.Contents.Value, "Version: "+tc.impact) {
+			t.Fatalf("Test case %d failed. Got %+v - Exp %q", idx, response.Result, tc.impact)
+		}
+		if *response.Result.Range != tc.word {
+			t.Fatalf("Test case %d failed. Got range %+v - Exp %+v", idx, *response.Result.Range, tc.word)
+		}
+	}
+
+	// blanks and unknown words have no hover
+	for _, position := range []lsp.Position{{Line: 0, Character: 12}, {Line: 0, Character: 11}, {Line: 5, Character: 0}} {
+		if response := state.Hover(1, uri, position); response.Result != nil {
+			t.Fatalf("there should be no hover at %+v, got %+v", position, response.Result)
+		}
+	}
+
+	state.Capabilities.TextDocument.Hover.ContentFormat = []string{lsp.Markdown, lsp.PlainText}
+	state.UpdateDocument(uri, "docs: update the readme")
+	response := state.Hover(1, uri, lsp.Position{Line: 0, Character: 0})
+	if response.Result.Contents.Kind != lsp.Markdown {
+		t.Fatalf("the client supports markdown, got %s", response.Result.Contents.Kind)
+	}
+	if expected := "**Version:** docs -> no version bump: 1.4.2"; !strings.HasSuffix(response.Result.Contents.Value, expected) {
+		t.Fatalf("Got %q - Exp %q", response.Result.Contents.Value, expected)
+	}
+	// a feature since the release makes the next version a minor one, like
+	// in the changelog preview
+	cmd := exec.Command("git", "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--allow-empty", "--message", "feat: add search")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %s", out)
+	}
+	state.UpdateDocument(uri, "fix: off by one")
+	response = state.Hover(1, uri, lsp.Position{Line: 0, Character: 0})
+	if expected := "**Version:** fix -> patch bump: 1.4.2 -> 1.5.0 (minor bump with the earlier commits)"; !strings.HasSuffix(response.Result.Contents.Value, expected) {
+		t.Fatalf("Got %q - Exp %q", response.Result.Contents.Value, expected)
+	}
+}
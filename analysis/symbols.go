@@ -0,0 +1,156 @@
+package analysis
+
+import (
+	"cc-lsp/conventional"
+	"cc-lsp/lsp"
+	"strings"
+)
+
+// the symbol kinds of the protocol that are used for the outline
+const (
+	fileSymbol       = 1
+	moduleSymbol     = 2
+	namespaceSymbol  = 3
+	propertySymbol   = 7
+	stringSymbol     = 15
+	enumMemberSymbol = 22
+)
+
+// documentSymbols returns the outline of the message: the header with its
+// parts, the body, the footers and the files of the diff
+func documentSymbols(document string) []lsp.DocumentSymbol {
+	commit := conventional.Parse(document)
+	lines := strings.Split(document, "\n")
+	symbols := []lsp.DocumentSymbol{}
+
+	if header := commit.Header; header != nil {
+		line := strings.TrimRight(lines[header.Line], "\r")
+		headerRange := LineRange(header.Line, 0, len(line))
+		children := []lsp.DocumentSymbol{}
+		add := func(name string, kind int, r lsp.Range) {
+			if r.End.Character > r.Start.Character {
+				children = append(children, lsp.DocumentSymbol{Name: name, Detail: line[r.Start.Character:r.End.Character], Kind: kind, Range: r, SelectionRange: r})
+			}
+		}
+		add("type", enumMemberSymbol, header.Type)
+		add("scope", moduleSymbol, header.Scope)
+		add("description", stringSymbol, header.Description)
+		symbols = append(symbols, lsp.DocumentSymbol{
+			Name:           "Header",
+			Detail:         line,
+			Kind:           namespaceSymbol,
+			Range:          headerRange,
+			SelectionRange: headerRange,
+			Children:       children,
+		})
+	}
+
+	if commit.Body != "" {
+		symbols = append(symbols, lsp.DocumentSymbol{
+			Name:           "Body",
+			Kind:           stringSymbol,
+			Range:          commit.BodyRange,
+			SelectionRange: LineRange(commit.BodyRange.Start.Line, 0, 0),
+		})
+	}
+
+	if len(commit.Footers) > 0 {
+		children := []lsp.DocumentSymbol{}
+		for _, footer := range commit.Footers {
+			values := footer.ValueRanges
+			children = append(children, lsp.DocumentSymbol{
+				Name:           footer.Token,
+				Detail:         footer.Value,
+				Kind:           propertySymbol,
+				Range:          lsp.Range{Start: footer.TokenRange.Start, End: values[len(values)-1].End},
+				SelectionRange: footer.TokenRange,
+			})
+		}
+		symbols = append(symbols, lsp.DocumentSymbol{
+			Name:           "Footers",
+			Kind:           namespaceSymbol,
+			Range:          lsp.Range{Start: children[0].Range.Start, End: children[len(children)-1].Range.End},
+			SelectionRange: children[0].SelectionRange,
+			Children:       children,
+		})
+	}
+
+	if commit.Scissors >= 0 {
+		children := []lsp.DocumentSymbol{}
+		for _, file := range commit.Diff {
+			children = append(children, lsp.DocumentSymbol{
+				Name:           file.Name,
+				Kind:           fileSymbol,
+				Range:          file.Range,
+				SelectionRange: LineRange(file.Range.Start.Line, 0, len(strings.TrimRight(lines[file.Range.Start.Line], "\r"))),
+			})
+		}
+		scissors := LineRange(commit.Scissors, 0, len(conventional.ScissorsLine))
+		diffRange := scissors
+		if len(children) > 0 {
+			diffRange.End = children[len(children)-1].Range.End
+		}
+		symbols = append(symbols, lsp.DocumentSymbol{
+			Name:           "Diff",
+			Kind:           namespaceSymbol,
+			Range:          diffRange,
+			SelectionRange: scissors,
+			Children:       children,
+		})
+	}
+	return symbols
+}
+
+// foldingRanges folds the blocks of comments, the diff below the scissors
+// line and every file and hunk of it
+func foldingRanges(document string) []lsp.FoldingRange {
+	commit := conventional.Parse(document)
+	folds := []lsp.FoldingRange{}
+	add := func(start, end int, kind string) {
+		if end > start {
+			folds = append(folds, lsp.FoldingRange{StartLine: start, EndLine: end, Kind: kind})
+		}
+	}
+
+	// consecutive comment lines make a block
+	for idx := 0; idx < len(commit.Comments); {
+		end := idx
+		for end+1 < len(commit.Comments) && commit.Comments[end+1] == commit.Comments[end]+1 {
+			end++
+		}
+		add(commit.Comments[idx], commit.Comments[end], lsp.CommentFold)
+		idx = end + 1
+	}
+
+	if commit.Scissors >= 0 {
+		end := len(strings.Split(strings.TrimRight(document, "\n"), "\n")) - 1
+		add(commit.Scissors, end, lsp.RegionFold)
+	}
+	for _, file := range commit.Diff {
+		add(file.Range.Start.Line, file.Range.End.Line, lsp.RegionFold)
+		for _, hunk := range file.Hunks {
+			add(hunk.Start.Line, hunk.End.Line, lsp.RegionFold)
+		}
+	}
+	return folds
+}
+
+func (s *State) DocumentSymbols(id int, uri string) lsp.DocumentSymbolResponse {
+	return lsp.DocumentSymbolResponse{
+		Response: lsp.Response{
+			RPC: "2.0",
+			ID:  &id,
+		},
+		Result: documentSymbols(s.Documents[uri]),
+	}
+}
+
+func (s *State) FoldingRanges(id int, uri string) lsp.FoldingRangeResponse {
+	return lsp.FoldingRangeResponse{
+		Response: lsp.Response{
+			RPC: "2.0",
+			ID:  &id,
+		},
+		Result: foldingRanges(s.Documents[uri]),
+	}
+}
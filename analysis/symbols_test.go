@@ -0,0 +1,47 @@
+package analysis
+
+import (
+	"cc-lsp/conventional"
+	"cc-lsp/lsp"
+	"reflect"
+	"testing"
+)
+
+const verboseMessage = "feat(api): add paging\n\nThe list endpoints\nreturn pages now.\n\nRefs: #12\n# Please enter the commit message\n# for your changes.\n" +
+	conventional.ScissorsLine + "\n# Do not modify or remove the line above.\ndiff --git a/api.go b/api.go\nindex 1..2\n@@ -1,2 +1,3 @@\n a\n+b\n@@ -9 +10 @@\n c\ndiff --git a/readme.md b/readme.md\n@@ -1 +1 @@\n-x\n+y\n"
+
+func TestDocumentSymbols(t *testing.T) {
+	symbols := documentSymbols(verboseMessage)
+	names := []string{}
+	for _, symbol := range symbols {
+		names = append(names, symbol.Name)
+		for _, child := range symbol.Children {
+			names = append(names, symbol.Name+"/"+child.Name)
+		}
+	}
+	expected := []string{"Header", "Header/type", "Header/scope", "Header/description", "Body", "Footers", "Footers/Refs", "Diff", "Diff/api.go", "Diff/readme.md"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Got %v - Exp %v", names, expected)
+	}
+	if body := symbols[1].Range; body.Start.Line != 2 || body.End != (lsp.Position{Line: 3, Character: 17}) {
+		t.Fatalf("body range is %+v", body)
+	}
+	if file := symbols[3].Children[0].Range; file.Start.Line != 10 || file.End.Line != 16 {
+		t.Fatalf("file range is %+v", file)
+	}
+}
+
+func TestFoldingRanges(t *testing.T) {
+	expected := []lsp.FoldingRange{
+		{StartLine: 6, EndLine: 7, Kind: lsp.CommentFold},
+		{StartLine: 8, EndLine: 20, Kind: lsp.RegionFold},
+		{StartLine: 10, EndLine: 16, Kind: lsp.RegionFold},
+		{StartLine: 12, EndLine: 14, Kind: lsp.RegionFold},
+		{StartLine: 15, EndLine: 16, Kind: lsp.RegionFold},
+		{StartLine: 17, EndLine: 20, Kind: lsp.RegionFold},
+		{StartLine: 18, EndLine: 20, Kind: lsp.RegionFold},
+	}
+	if actual := foldingRanges(verboseMessage); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Got %+v - Exp %+v", actual, expected)
+	}
+}
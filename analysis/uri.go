@@ -0,0 +1,34 @@
+package analysis
+
+import (
+	"cc-lsp/git"
+	"fmt"
+	"net/url"
+	"path/filepath"
+)
+
+// uriToPath converts a file URI sent by the client to a path
+func uriToPath(uri string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Scheme != "file" {
+		return "", fmt.Errorf("%s is not a file", uri)
+	}
+	return filepath.FromSlash(parsed.Path), nil
+}
+
+// pathToURI converts a path to a file URI for the client
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// repoForURI returns the git repository that owns the document
+func repoForURI(uri string) (git.Repo, error) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return git.Repo{}, err
+	}
+	return git.OpenForFile(path)
+}
@@ -0,0 +1,75 @@
+package analysis
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/conventional"
+	"cc-lsp/git"
+	"cc-lsp/release"
+	"errors"
+	"fmt"
+)
+
+// versionImpact describes what the commit does to the latest release of the
+// repository that owns the document, e.g. "feat -> minor bump: 1.4.2 -> 1.5.0".
+// The next version is previewed like in the changelog lens, so the commits
+// since the release count too. In a monorepo the release of the package the
+// scope belongs to is used.
+func (s *State) versionImpact(uri string, commit conventional.Commit) (string, error) {
+	if !commit.Conventional {
+		return "", errors.New("not a conventional commit")
+	}
+	repo, err := repoForURI(uri)
+	if err != nil {
+		return "", err
+	}
+	cfg, err := s.config(repo)
+	if err != nil {
+		return "", err
+	}
+
+	options := releaseOptions(cfg, commit)
+	plan, err := release.PreviewVersion(repo, options, git.Commit{Message: s.Documents[uri], Files: s.staged[uri]})
+	if err != nil {
+		return "", err
+	}
+
+	current := release.Version{}
+	name := ""
+	if plan.Latest != nil {
+		current = plan.Latest.Version
+	}
+	if options.Package != nil {
+		name = options.Package.Name + " "
+	}
+
+	kind := commit.Type
+	if commit.Breaking {
+		kind = "breaking change"
+	}
+	bump := release.BumpFor(commit)
+	impact := fmt.Sprintf("%s -> %s bump", kind, bump)
+	if bump == release.NoBump {
+		impact = kind + " -> no version bump"
+	}
+	if plan.Bump == release.NoBump {
+		return fmt.Sprintf("%s: %s%s", impact, name, current), nil
+	}
+	impact = fmt.Sprintf("%s: %s%s -> %s", impact, name, current, plan.Next)
+	if plan.Bump != bump {
+		impact += fmt.Sprintf(" (%s bump with the earlier commits)", plan.Bump)
+	}
+	return impact, nil
+}
+
+// releaseOptions selects the release the commit belongs to, in a monorepo the
+// one of the package the scope belongs to
+func releaseOptions(cfg config.Config, commit conventional.Commit) release.Options {
+	var options release.Options
+	for _, pkg := range cfg.Packages {
+		if commit.Scope != "" && pkg.HasScope(commit.Scope) {
+			options.Package = &pkg
+			break
+		}
+	}
+	return options
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/git"
+	"cc-lsp/release"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// command is a sub command of the cc-lsp binary, without one the language
+// server is started
+type command func(args []string, stdout, stderr io.Writer) error
+
+var commands = map[string]command{
+	"next-version": nextVersionCommand,
+	"release":      releaseCommand,
+}
+
+// errUsage is returned for invalid flags, the flag set already printed the usage
+var errUsage = errors.New("usage")
+
+func runCommand(name string, args []string) int {
+	cmd := commands[name]
+	err := cmd(args, os.Stdout, os.Stderr)
+	if errors.Is(err, errUsage) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cc-lsp %s: %s\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+func openRepo() (git.Repo, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return git.Repo{}, err
+	}
+	return git.Open(dir)
+}
+
+// addReleaseFlags registers the flags that change the version calculation
+func addReleaseFlags(flags *flag.FlagSet, options *release.Options, pkg *string) {
+	flags.StringVar(&options.Channel, "pre", "", "pre-release `channel`, e.g. rc for 1.2.0-rc.1")
+	flags.BoolVar(&options.ZeroMajor, "zero", false, "0.x mode: breaking changes bump the minor version while the major version is 0")
+	flags.StringVar(pkg, "package", "", "only release the monorepo package with this `name`")
+}
+
+// releaseTargets returns the options for every release the command works on:
+// the whole repository, or each package if the config defines packages
+func releaseTargets(repo git.Repo, options release.Options, name string) ([]release.Options, error) {
+	cfg, err := config.Load(repo.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		pkg, ok := cfg.Package(name)
+		if !ok {
+			return nil, fmt.Errorf("package %s is not defined in %s", name, config.File)
+		}
+		options.Package = &pkg
+		return []release.Options{options}, nil
+	}
+	if len(cfg.Packages) == 0 {
+		return []release.Options{options}, nil
+	}
+
+	targets := []release.Options{}
+	for _, pkg := range cfg.Packages {
+		options.Package = &pkg
+		targets = append(targets, options)
+	}
+	return targets, nil
+}
+
+func nextVersionCommand(args []string, stdout, stderr io.Writer) error {
+	var options release.Options
+	var pkg string
+	flags := flag.NewFlagSet("next-version", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	addReleaseFlags(flags, &options, &pkg)
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	repo, err := openRepo()
+	if err != nil {
+		return err
+	}
+	targets, err := releaseTargets(repo, options, pkg)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		plan, err := release.NextVersion(repo, target)
+		if err != nil {
+			return err
+		}
+		name := ""
+		if target.Package != nil && pkg == "" {
+			name = target.Package.Name + " "
+		}
+		if plan.Bump == release.NoBump {
+			fmt.Fprintf(stderr, "%sno feat, fix, perf or breaking commits since the latest release\n", name)
+		}
+		fmt.Fprintf(stdout, "%s%s\n", name, plan.Next)
+	}
+	return nil
+}
+
+func releaseCommand(args []string, stdout, stderr io.Writer) error {
+	var options release.Options
+	var pkg string
+	flags := flag.NewFlagSet("release", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	addReleaseFlags(flags, &options, &pkg)
+	dryRun := flags.Bool("dry-run", false, "print the planned actions without changing anything")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	repo, err := openRepo()
+	if err != nil {
+		return err
+	}
+	targets, err := releaseTargets(repo, options, pkg)
+	if err != nil {
+		return err
+	}
+
+	released := false
+	for _, target := range targets {
+		plan, err := release.NextVersion(repo, target)
+		if err != nil {
+			return err
+		}
+		if plan.Bump == release.NoBump {
+			continue
+		}
+		released = true
+		if err := releasePlan(repo, target, plan, *dryRun, stdout); err != nil {
+			return err
+		}
+	}
+	if !released {
+		return errors.New("nothing to release, no feat, fix, perf or breaking commits since the latest release")
+	}
+	return nil
+}
+
+func releasePlan(repo git.Repo, options release.Options, plan release.Plan, dryRun bool, stdout io.Writer) error {
+	notes := release.RenderNotes(plan.Next, time.Now(), plan.Commits)
+	changelog := options.ChangelogPath()
+	scope := "changelog"
+	if options.Package != nil {
+		scope = options.Package.Name
+	}
+	message := fmt.Sprintf("docs(%s): release %s", scope, plan.Next)
+	if dryRun {
+		fmt.Fprintf(stdout, "would add the release notes to %s\n", changelog)
+		fmt.Fprintf(stdout, "would commit %s with message %q\n", changelog, message)
+		fmt.Fprintf(stdout, "would create tag %s with message:\n\n%s\n", plan.TagName, notes)
+		return nil
+	}
+
+	if err := release.UpdateChangelog(filepath.Join(repo.Dir, changelog), notes); err != nil {
+		return err
+	}
+	if err := repo.CommitFiles(message, changelog); err != nil {
+		return err
+	}
+	if err := repo.CreateTag(plan.TagName, notes); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "released %s\n", plan.TagName)
+	return nil
+}
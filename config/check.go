@@ -0,0 +1,119 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Options lists the options of the config file
+var Options = []string{"packages", "scopes", "preset", "rules", "format"}
+
+// Problem is a mistake in a config file. Line is the line it is on, -1 if
+// only the Key it belongs to is known.
+type Problem struct {
+	Line    int
+	Key     string
+	Level   Level
+	Message string
+}
+
+// Check reports every problem of the content of a config file, unlike
+// ParseFile which stops at the first one. Unknown options are warnings, the
+// config can still be read.
+func Check(name string, content []byte) []Problem {
+	if name == YAMLFile {
+		converted, err := yamlToJSON(content)
+		var yamlErr *yamlError
+		if errors.As(err, &yamlErr) {
+			return []Problem{{Line: yamlErr.line - 1, Level: Error, Message: yamlErr.message}}
+		}
+		content = converted
+	}
+
+	var options map[string]json.RawMessage
+	if err := json.Unmarshal(content, &options); err != nil {
+		line := 0
+		if name != YAMLFile {
+			line = jsonErrorLine(content, err)
+		}
+		return []Problem{{Line: line, Level: Error, Message: err.Error()}}
+	}
+
+	problems := []Problem{}
+	for _, key := range sortedKeys(options) {
+		value := options[key]
+		switch {
+		case key == "rules":
+			problems = append(problems, checkRules(value)...)
+		case slices.Contains(Options, key):
+			if err := checkOption(key, value); err != nil {
+				problems = append(problems, Problem{Line: -1, Key: key, Level: Error, Message: err.Error()})
+			}
+		default:
+			problems = append(problems, Problem{Line: -1, Key: key, Level: Warning, Message: fmt.Sprintf("unknown option %s, it is ignored", key)})
+		}
+	}
+	return problems
+}
+
+// checkOption reads and validates a single option
+func checkOption(key string, value json.RawMessage) error {
+	content, err := json.Marshal(map[string]json.RawMessage{key: value})
+	if err != nil {
+		return err
+	}
+	var config Config
+	if err := json.Unmarshal(content, &config); err != nil {
+		return err
+	}
+	return config.validate()
+}
+
+func checkRules(value json.RawMessage) []Problem {
+	var rules map[string]json.RawMessage
+	if err := json.Unmarshal(value, &rules); err != nil {
+		return []Problem{{Line: -1, Key: "rules", Level: Error, Message: "rules must map rule names to [level, applicable, value]"}}
+	}
+	problems := []Problem{}
+	for _, name := range sortedKeys(rules) {
+		var rule Rule
+		err := json.Unmarshal(rules[name], &rule)
+		if err == nil || !slices.Contains(RuleNames, name) {
+			err = checkRule(name, rule)
+		} else {
+			err = fmt.Errorf("rule %s: %w", name, err)
+		}
+		if err != nil {
+			problems = append(problems, Problem{Line: -1, Key: name, Level: Error, Message: err.Error()})
+		}
+	}
+	return problems
+}
+
+// jsonErrorLine returns the line of a syntax or type error
+func jsonErrorLine(content []byte, err error) int {
+	offset := int64(0)
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	}
+	offset = min(offset, int64(len(content)))
+	return bytes.Count(content[:offset], []byte("\n"))
+}
+
+func sortedKeys(values map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	cases := []struct {
+		name     string
+		content  string
+		expected []Problem
+	}{
+		{File, `{"preset": "strict", "rules": {"header-max-length": [2, "always", 50]}}`, []Problem{}},
+		{File, "{\n  \"rules\": {\n    \"header-max-length\": [2, \"always\" 50]\n  }\n}", []Problem{{Line: 2, Level: Error}}},
+		{File, `{"rules": {"no-such-rule": [2], "scope-enum": [3], "header-max-length": ["error"]}, "formt": {}}`, []Problem{
+			{Line: -1, Key: "formt", Level: Warning, Message: "unknown option formt, it is ignored"},
+			{Line: -1, Key: "header-max-length", Level: Error},
+			{Line: -1, Key: "no-such-rule", Level: Error, Message: "unknown rule no-such-rule"},
+			{Line: -1, Key: "scope-enum", Level: Error, Message: "rule scope-enum: the level must be 0, 1 or 2, got 3"},
+		}},
+		{File, `{"preset": "lax", "format": {"wrapColumn": -1}}`, []Problem{
+			{Line: -1, Key: "format", Level: Error, Message: "format.wrapColumn must be positive, got -1"},
+			{Line: -1, Key: "preset", Level: Error, Message: "unknown preset lax, expected one of recommended, strict, minimal"},
+		}},
+		{YAMLFile, "rules:\n  scope-enum: [1, sometimes]\n", []Problem{{Line: -1, Key: "scope-enum", Level: Error, Message: "rule scope-enum: applicable must be always or never, got sometimes"}}},
+		{YAMLFile, "rules:\n  scope-enum: [1, always, api]\n", []Problem{{Line: -1, Key: "scope-enum", Level: Error, Message: `rule scope-enum: the value must be a list of strings, got "api"`}}},
+		{YAMLFile, "rules:\n  scope-enum: [1, always\n", []Problem{{Line: 1, Level: Error, Message: "missing ]"}}},
+		{YAMLFile, "- rules\n", []Problem{{Line: 0, Level: Error}}},
+	}
+
+	// the messages of encoding/json are not compared
+	for idx, tc := range cases {
+		actual := Check(tc.name, []byte(tc.content))
+		for i := range actual {
+			if i < len(tc.expected) && tc.expected[i].Message == "" {
+				actual[i].Message = ""
+			}
+		}
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Fatalf("Test case %d failed. Got %+v - Exp %+v", idx, actual, tc.expected)
+		}
+	}
+}
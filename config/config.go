@@ -0,0 +1,263 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// File is the name of the config file in the root of the repository
+const File = ".cc-lsp.json"
+
+// the other config files, .cc-lsp.yaml is read like .cc-lsp.json and only
+// the rules cc-lsp knows are read from the commitlint config
+const (
+	YAMLFile       = ".cc-lsp.yaml"
+	CommitlintFile = ".commitlintrc.json"
+)
+
+// Files lists the config files in the order they are looked up, the first
+// one found is read
+var Files = []string{File, YAMLFile, CommitlintFile}
+
+type Config struct {
+	// Packages are the independently versioned packages of a monorepo
+	Packages []Package   `json:"packages"`
+	Scopes   ScopeConfig `json:"scopes"`
+	// Preset selects the defaults of the rules the config does not mention,
+	// DefaultRules if it is not set
+	Preset string `json:"preset"`
+	// Rules maps rule names to their configuration
+	Rules  map[string]Rule `json:"rules"`
+	Format FormatConfig    `json:"format"`
+}
+
+// DefaultWrapColumn is the column the body is wrapped at by default
+const DefaultWrapColumn = 72
+
+type FormatConfig struct {
+	// WrapColumn is the column the body is wrapped at, DefaultWrapColumn if
+	// it is not set
+	WrapColumn int `json:"wrapColumn"`
+}
+
+// Column returns the configured or the default wrap column
+func (f FormatConfig) Column() int {
+	if f.WrapColumn == 0 {
+		return DefaultWrapColumn
+	}
+	return f.WrapColumn
+}
+
+// the providers that discover scopes
+const (
+	HistoryProvider     = "history"
+	GoProvider          = "go"
+	NpmProvider         = "npm"
+	CargoProvider       = "cargo"
+	DirectoriesProvider = "directories"
+)
+
+// ScopeProviders lists all providers in the order their scopes are offered
+var ScopeProviders = []string{HistoryProvider, GoProvider, NpmProvider, CargoProvider, DirectoriesProvider}
+
+type ScopeConfig struct {
+	// Providers that discover scopes, all providers run if it is not set
+	Providers []string `json:"providers"`
+}
+
+// HasProvider reports whether the scope provider is enabled
+func (s ScopeConfig) HasProvider(name string) bool {
+	return s.Providers == nil || slices.Contains(s.Providers, name)
+}
+
+// Package is a part of a monorepo with its own versions, tags and changelog
+type Package struct {
+	Name string `json:"name"`
+	// Path is the directory of the package relative to the repository root
+	Path string `json:"path"`
+	// Scopes that belong to the package, defaults to the name of the package
+	Scopes []string `json:"scopes"`
+}
+
+// Load reads the config from the repository root in dir, without a config
+// file the default config is used
+func Load(dir string) (Config, error) {
+	for _, name := range Files {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return Config{}, err
+		}
+		return ParseFile(name, content)
+	}
+	return Config{}, nil
+}
+
+// Parse parses and validates the content of a config file
+func Parse(content []byte) (Config, error) {
+	return ParseFile(File, content)
+}
+
+// ParseFile parses and validates the content of one of the config Files
+func ParseFile(name string, content []byte) (Config, error) {
+	var config Config
+	var err error
+	switch name {
+	case YAMLFile:
+		if content, err = yamlToJSON(content); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", name, err)
+		}
+		err = json.Unmarshal(content, &config)
+	case CommitlintFile:
+		config, err = parseCommitlint(content)
+	default:
+		err = json.Unmarshal(content, &config)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", name, err)
+	}
+	if err := config.validate(); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return config, nil
+}
+
+// parseCommitlint reads the rules of a commitlint config, the rules cc-lsp
+// does not know are left to commitlint
+func parseCommitlint(content []byte) (Config, error) {
+	var commitlint struct {
+		Rules map[string]json.RawMessage `json:"rules"`
+	}
+	if err := json.Unmarshal(content, &commitlint); err != nil {
+		return Config{}, err
+	}
+	config := Config{}
+	for name, value := range commitlint.Rules {
+		if !slices.Contains(RuleNames, name) {
+			continue
+		}
+		var rule Rule
+		if err := json.Unmarshal(value, &rule); err != nil {
+			return Config{}, fmt.Errorf("rule %s: %w", name, err)
+		}
+		if config.Rules == nil {
+			config.Rules = map[string]Rule{}
+		}
+		config.Rules[name] = rule
+	}
+	return config, nil
+}
+
+func (c Config) validate() error {
+	names := map[string]bool{}
+	for idx, pkg := range c.Packages {
+		if pkg.Name == "" {
+			return fmt.Errorf("package %d has no name", idx)
+		}
+		if strings.ContainsAny(pkg.Name, " ~^:?*[\\") {
+			return fmt.Errorf("package %s: the name is used in tags and cannot contain spaces or any of ~^:?*[\\", pkg.Name)
+		}
+		if names[pkg.Name] {
+			return fmt.Errorf("package %s is defined twice", pkg.Name)
+		}
+		names[pkg.Name] = true
+	}
+	for _, provider := range c.Scopes.Providers {
+		if !slices.Contains(ScopeProviders, provider) {
+			return fmt.Errorf("unknown scope provider %s, expected one of %s", provider, strings.Join(ScopeProviders, ", "))
+		}
+	}
+	if _, ok := Presets[c.Preset]; c.Preset != "" && !ok {
+		return fmt.Errorf("unknown preset %s, expected one of %s", c.Preset, strings.Join(PresetNames, ", "))
+	}
+	if c.Format.WrapColumn < 0 {
+		return fmt.Errorf("format.wrapColumn must be positive, got %d", c.Format.WrapColumn)
+	}
+	for name, rule := range c.Rules {
+		if err := checkRule(name, rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkRule(name string, rule Rule) error {
+	if !slices.Contains(RuleNames, name) {
+		return fmt.Errorf("unknown rule %s", name)
+	}
+	if err := rule.validate(); err != nil {
+		return fmt.Errorf("rule %s: %w", name, err)
+	}
+	switch name {
+	case HeaderMaxLength:
+		if _, err := rule.Int(DefaultHeaderMaxLength); err != nil {
+			return fmt.Errorf("rule %s: %w", name, err)
+		}
+	case ScopeEnum:
+		if _, err := rule.Strings(); err != nil {
+			return fmt.Errorf("rule %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Package returns the package with the given name
+func (c Config) Package(name string) (Package, bool) {
+	for _, pkg := range c.Packages {
+		if pkg.Name == name {
+			return pkg, true
+		}
+	}
+	return Package{}, false
+}
+
+// PackageScopes returns the scopes of all packages
+func (c Config) PackageScopes() []string {
+	scopes := []string{}
+	for _, pkg := range c.Packages {
+		if len(pkg.Scopes) == 0 {
+			scopes = append(scopes, pkg.Name)
+		}
+		scopes = append(scopes, pkg.Scopes...)
+	}
+	return scopes
+}
+
+// TagPrefix is put in front of the version in the tags of the package,
+// e.g. billing/ for billing/v1.4.0
+func (p Package) TagPrefix() string {
+	return p.Name + "/"
+}
+
+// HasScope reports whether the commit scope belongs to the package
+func (p Package) HasScope(scope string) bool {
+	if len(p.Scopes) == 0 {
+		return scope == p.Name
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HasFile reports whether the file, relative to the repository root, is
+// part of the package
+func (p Package) HasFile(file string) bool {
+	if p.Path == "" {
+		return false
+	}
+	dir := strings.Trim(path.Clean(filepath.ToSlash(p.Path)), "/")
+	if dir == "." {
+		return false
+	}
+	return strings.HasPrefix(file, dir+"/")
+}
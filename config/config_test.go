@@ -0,0 +1,139 @@
+package config
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		content string
+		valid   bool
+	}{
+		{`{}`, true},
+		{`{"packages": [{"name": "billing", "path": "services/billing"}]}`, true},
+		{`{"packages": [{"name": "billing"}, {"name": "billing"}]}`, false},
+		{`{"packages": [{"path": "services/billing"}]}`, false},
+		{`{"packages": [{"name": "bil ling"}]}`, false},
+		{`{"packages": `, false},
+	}
+
+	for idx, tc := range cases {
+		_, err := Parse([]byte(tc.content))
+		if (err == nil) != tc.valid {
+			t.Fatalf("Test case %d failed. Got error %v", idx, err)
+		}
+	}
+}
+
+func TestPackageMembership(t *testing.T) {
+	billing := Package{Name: "billing", Path: "./services/billing/"}
+	auth := Package{Name: "auth", Scopes: []string{"login", "session"}}
+
+	if !billing.HasFile("services/billing/invoice.go") || billing.HasFile("services/billing-v2/invoice.go") || billing.HasFile("services/billing") {
+		t.Fatal("billing should only contain the files below services/billing")
+	}
+	if root := (Package{Name: "root", Path: "."}); root.HasFile("main.go") {
+		t.Fatal("a package at the repository root should not contain any files")
+	}
+	if auth.HasFile("services/auth/login.go") {
+		t.Fatal("auth has no path and should not contain any files")
+	}
+	if !billing.HasScope("billing") || billing.HasScope("auth") {
+		t.Fatal("billing should default to its name as scope")
+	}
+	if !auth.HasScope("session") || auth.HasScope("auth") {
+		t.Fatal("auth should only have the configured scopes")
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	cases := []struct {
+		content string
+		valid   bool
+	}{
+		{`{"rules": {"scope-enum": [2, "always", ["api", "cli"]]}}`, true},
+		{`{"rules": {"scope-enum": [0]}}`, true},
+		{`{"rules": {"scope-enum": [3, "always", []]}}`, false},
+		{`{"rules": {"scope-enum": [1, "sometimes", []]}}`, false},
+		{`{"rules": {"scope-enum": {"level": 2}}}`, false},
+		{`{"rules": {"scope-enum": [2, "always", "api"]}}`, false},
+		{`{"rules": {"scope-enum": [2, "always", [1, 2]]}}`, false},
+		{`{"rules": {"no-such-rule": [2]}}`, false},
+		{`{"rules": {"header-max-length": [2, "always", 100]}}`, true},
+		{`{"rules": {"header-max-length": [2, "always", "long"]}}`, false},
+		{`{"rules": {"header-max-length": [2, "always", 0]}}`, false},
+		{`{"scopes": {"providers": ["go", "history"]}}`, true},
+		{`{"scopes": {"providers": ["maven"]}}`, false},
+	}
+
+	for idx, tc := range cases {
+		_, err := Parse([]byte(tc.content))
+		if (err == nil) != tc.valid {
+			t.Fatalf("Test case %d failed. Got error %v", idx, err)
+		}
+	}
+
+	config, err := Parse([]byte(`{"rules": {"scope-enum": [1, "always", ["api", "cli"]]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule, ok := config.Rule(ScopeEnum)
+	values, err := rule.Strings()
+	if !ok || err != nil || rule.Level != Warning || len(values) != 2 {
+		t.Fatalf("scope-enum should be a warning with two scopes, got %+v", rule)
+	}
+	if _, ok := (Config{}).Rule(ScopeEnum); ok {
+		t.Fatal("rules should be disabled by default")
+	}
+}
+
+func TestFormatConfig(t *testing.T) {
+	cfg, err := Parse([]byte(`{"format": {"wrapColumn": 80}}`))
+	if err != nil || cfg.Format.Column() != 80 {
+		t.Fatalf("Got %d, %v - Exp 80", cfg.Format.Column(), err)
+	}
+	if column := (Config{}).Format.Column(); column != DefaultWrapColumn {
+		t.Fatalf("Got %d - Exp %d", column, DefaultWrapColumn)
+	}
+	if _, err := Parse([]byte(`{"format": {"wrapColumn": -1}}`)); err == nil {
+		t.Fatal("a negative wrap column should be invalid")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	repo, err := Parse([]byte(`{"preset": "strict", "rules": {"scope-enum": [2, "always", ["api"]], "header-max-length": [1, "always", 50]}, "format": {"wrapColumn": 80}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, err := ParseSettings([]byte(`{"rules": {"header-max-length": [0]}, "format": {"wrapColumn": 100}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := repo.Merge(settings)
+
+	cases := []struct {
+		rule  string
+		level Level
+	}{
+		{ScopeEnum, Error},
+		{HeaderMaxLength, Disabled},
+		{GoAPIBreaking, Error},
+	}
+	for idx, tc := range cases {
+		if rule, _ := merged.Rule(tc.rule); rule.Level != tc.level {
+			t.Fatalf("Test case %d failed. Got %d - Exp %d", idx, rule.Level, tc.level)
+		}
+	}
+	if merged.Format.Column() != 100 || repo.Rules[HeaderMaxLength].Level != Warning {
+		t.Fatalf("the settings should override the config without changing it, got %+v", merged)
+	}
+
+	minimal := repo.Merge(Config{Preset: MinimalPreset})
+	if _, ok := minimal.Rule(GoAPIBreaking); ok {
+		t.Fatal("the preset of the settings should replace the one of the config")
+	}
+	if _, err := ParseSettings([]byte(`{"preset": "lax"}`)); err == nil {
+		t.Fatal("unknown presets should be rejected")
+	}
+	if settings, err := ParseSettings(nil); err != nil || settings.Preset != "" {
+		t.Fatalf("missing settings should be empty, got %+v %v", settings, err)
+	}
+}
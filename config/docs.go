@@ -0,0 +1,37 @@
+package config
+
+// RuleDocs describe the rules
+var RuleDocs = map[string]string{
+	ScopeEnum:             "The scope must be one of the listed scopes, one found in the project layout or one of a package, the scopes of the history are not allowed. With never the listed scopes must not be used. The value is the list of scopes.",
+	TypeStagedConsistency: "Warns if the type contradicts the staged files, e.g. docs with staged source files or test without staged test files.",
+	GoAPIBreaking:         "Compares the exported Go API of the staged files with HEAD and reports removed or changed identifiers if the commit is not marked as a breaking change.",
+	HeaderMaxLength:       "The header must not be longer than the value in characters, 72 without a value.",
+}
+
+// OptionDocs describe the options of the config file
+var OptionDocs = map[string]string{
+	"packages":   "The independently versioned packages of a monorepo, each with a name, a path and the scopes that belong to it.",
+	"scopes":     "Where scopes are discovered.",
+	"providers":  "The scope providers that run, all of history, go, npm, cargo and directories if it is not set.",
+	"preset":     "The defaults of the rules that are not configured: recommended, strict or minimal.",
+	"rules":      "The rules, configured like in commitlint as [level, applicable, value]. The level is 0 (disabled), 1 (warning) or 2 (error), applicable is always or never.",
+	"format":     "How messages are formatted.",
+	"wrapColumn": "The column the body is wrapped at, 72 if it is not set.",
+}
+
+// PresetDocs describe the presets
+var PresetDocs = map[string]string{
+	RecommendedPreset: "The default rules: type-staged-consistency, go-api-breaking and header-max-length as warnings.",
+	StrictPreset:      "The default rules as errors.",
+	MinimalPreset:     "Only header-max-length as a warning.",
+}
+
+// Documentation returns the description of a rule, an option or a preset
+func Documentation(name string) (string, bool) {
+	for _, docs := range []map[string]string{RuleDocs, OptionDocs, PresetDocs} {
+		if doc, ok := docs[name]; ok {
+			return doc, true
+		}
+	}
+	return "", false
+}
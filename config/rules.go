@@ -0,0 +1,157 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Level is the severity of a rule like in commitlint: 0 disables the rule,
+// 1 reports a warning and 2 an error
+type Level int
+
+const (
+	Disabled Level = iota
+	Warning
+	Error
+)
+
+// names of the rules
+const (
+	ScopeEnum             = "scope-enum"
+	TypeStagedConsistency = "type-staged-consistency"
+	GoAPIBreaking         = "go-api-breaking"
+	HeaderMaxLength       = "header-max-length"
+)
+
+// DefaultHeaderMaxLength is the limit of header-max-length without a value
+const DefaultHeaderMaxLength = 72
+
+// RuleNames lists the known rules
+var RuleNames = []string{
+	ScopeEnum,
+	TypeStagedConsistency,
+	GoAPIBreaking,
+	HeaderMaxLength,
+}
+
+// DefaultRules are used for the rules the config does not mention
+var DefaultRules = map[string]Rule{
+	TypeStagedConsistency: {Level: Warning, Applicable: "always"},
+	GoAPIBreaking:         {Level: Warning, Applicable: "always"},
+	HeaderMaxLength:       {Level: Warning, Applicable: "always"},
+}
+
+// the presets of the rules
+const (
+	RecommendedPreset = "recommended"
+	StrictPreset      = "strict"
+	MinimalPreset     = "minimal"
+)
+
+// PresetNames lists the known presets
+var PresetNames = []string{RecommendedPreset, StrictPreset, MinimalPreset}
+
+// Presets are the defaults of the rules a config can choose, recommended is
+// the same as DefaultRules
+var Presets = map[string]map[string]Rule{
+	RecommendedPreset: DefaultRules,
+	StrictPreset: {
+		TypeStagedConsistency: {Level: Error, Applicable: "always"},
+		GoAPIBreaking:         {Level: Error, Applicable: "always"},
+		HeaderMaxLength:       {Level: Error, Applicable: "always"},
+	},
+	MinimalPreset: {
+		HeaderMaxLength: {Level: Warning, Applicable: "always"},
+	},
+}
+
+// Rule is configured like in commitlint: [level, applicable, value], e.g.
+// "scope-enum": [2, "always", ["api", "cli"]]
+type Rule struct {
+	Level Level
+	// Applicable is either always or never, never inverts the rule
+	Applicable string
+	Value      json.RawMessage
+}
+
+func (r *Rule) UnmarshalJSON(content []byte) error {
+	var parts []json.RawMessage
+	if err := json.Unmarshal(content, &parts); err != nil {
+		return fmt.Errorf("a rule is an array of [level, applicable, value]: %w", err)
+	}
+	if len(parts) == 0 || len(parts) > 3 {
+		return fmt.Errorf("a rule is an array of [level, applicable, value], got %d elements", len(parts))
+	}
+
+	rule := Rule{Applicable: "always"}
+	if err := json.Unmarshal(parts[0], &rule.Level); err != nil {
+		return fmt.Errorf("the level must be 0, 1 or 2: %w", err)
+	}
+	if len(parts) > 1 {
+		if err := json.Unmarshal(parts[1], &rule.Applicable); err != nil {
+			return fmt.Errorf("applicable must be always or never: %w", err)
+		}
+	}
+	if len(parts) > 2 {
+		rule.Value = parts[2]
+	}
+	*r = rule
+	return nil
+}
+
+func (r Rule) MarshalJSON() ([]byte, error) {
+	parts := []any{r.Level, r.Applicable}
+	if r.Value != nil {
+		parts = append(parts, r.Value)
+	}
+	return json.Marshal(parts)
+}
+
+func (r Rule) validate() error {
+	if r.Level < Disabled || r.Level > Error {
+		return fmt.Errorf("the level must be 0, 1 or 2, got %d", r.Level)
+	}
+	if r.Applicable != "always" && r.Applicable != "never" {
+		return fmt.Errorf("applicable must be always or never, got %s", r.Applicable)
+	}
+	return nil
+}
+
+// Strings returns the value of the rule as a list of strings
+func (r Rule) Strings() ([]string, error) {
+	values := []string{}
+	if r.Value == nil {
+		return values, nil
+	}
+	if err := json.Unmarshal(r.Value, &values); err != nil {
+		return nil, fmt.Errorf("the value must be a list of strings, got %s", r.Value)
+	}
+	return values, nil
+}
+
+// Int returns the value of the rule as a positive number, the fallback if
+// the rule has no value
+func (r Rule) Int(fallback int) (int, error) {
+	if r.Value == nil {
+		return fallback, nil
+	}
+	var value int
+	if err := json.Unmarshal(r.Value, &value); err != nil || value <= 0 {
+		return 0, fmt.Errorf("the value must be a positive number, got %s", r.Value)
+	}
+	return value, nil
+}
+
+// Rule returns the configured rule or the one of the preset, ok is false if
+// it is missing or disabled
+func (c Config) Rule(name string) (Rule, bool) {
+	rule, ok := c.Rules[name]
+	if !ok {
+		preset, found := Presets[c.Preset]
+		if !found {
+			preset = DefaultRules
+		}
+		rule, ok = preset[name]
+	}
+	return rule, ok && rule.Level != Disabled
+}
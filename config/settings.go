@@ -0,0 +1,50 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+)
+
+// Section is the section of the editor settings that configures the server
+const Section = "cc-lsp"
+
+// ParseSettings parses and validates the settings the editor sends, they
+// have the same shape as the config file. Missing settings are empty.
+func ParseSettings(content json.RawMessage) (Config, error) {
+	var settings Config
+	if len(content) == 0 || string(content) == "null" {
+		return settings, nil
+	}
+	if err := json.Unmarshal(content, &settings); err != nil {
+		return Config{}, fmt.Errorf("%s settings: %w", Section, err)
+	}
+	if err := settings.validate(); err != nil {
+		return Config{}, fmt.Errorf("%s settings: %w", Section, err)
+	}
+	return settings, nil
+}
+
+// Merge returns the config with the settings on top: their preset, rules,
+// wrap column and scope providers override the ones of the config. Packages
+// belong to the repository and are only read from the config.
+func (c Config) Merge(settings Config) Config {
+	merged := c
+	if settings.Preset != "" {
+		merged.Preset = settings.Preset
+	}
+	if len(settings.Rules) > 0 {
+		merged.Rules = maps.Clone(c.Rules)
+		if merged.Rules == nil {
+			merged.Rules = map[string]Rule{}
+		}
+		maps.Copy(merged.Rules, settings.Rules)
+	}
+	if settings.Format.WrapColumn != 0 {
+		merged.Format.WrapColumn = settings.Format.WrapColumn
+	}
+	if settings.Scopes.Providers != nil {
+		merged.Scopes.Providers = settings.Scopes.Providers
+	}
+	return merged
+}
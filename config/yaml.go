@@ -0,0 +1,385 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// the config is small, so only a subset of YAML is read: block mappings and
+// sequences, flow sequences and mappings on a single line, plain and quoted
+// scalars and comments. Anchors, tags, block scalars and multiple documents
+// are not supported.
+
+// yamlError is a mistake on a line of the YAML content
+type yamlError struct {
+	line    int
+	message string
+}
+
+func (e *yamlError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.line, e.message)
+}
+
+func yamlErrorf(line int, format string, args ...any) error {
+	return &yamlError{line: line, message: fmt.Sprintf(format, args...)}
+}
+
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// yamlToJSON converts the YAML content to JSON so it can be parsed like the
+// JSON config
+func yamlToJSON(content []byte) ([]byte, error) {
+	lines, err := yamlLines(string(content))
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return []byte("{}"), nil
+	}
+	parser := yamlParser{lines: lines}
+	value, err := parser.block(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos < len(lines) {
+		line := lines[parser.pos]
+		return nil, yamlErrorf(line.number, "unexpected indentation")
+	}
+	return json.Marshal(value)
+}
+
+// yamlLines returns the lines with text without their comments
+func yamlLines(content string) ([]yamlLine, error) {
+	lines := []yamlLine{}
+	for idx, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(stripYAMLComment(line), " \t\r")
+		text := strings.TrimLeft(line, " ")
+		if text == "" || text == "---" {
+			continue
+		}
+		if strings.HasPrefix(text, "\t") {
+			return nil, yamlErrorf(idx+1, "tabs cannot indent YAML")
+		}
+		lines = append(lines, yamlLine{number: idx + 1, indent: len(line) - len(text), text: text})
+	}
+	return lines, nil
+}
+
+// stripYAMLComment removes a comment, a # at the start or after a space that
+// is not inside quotes
+func stripYAMLComment(line string) string {
+	var quote byte
+	for idx := 0; idx < len(line); idx++ {
+		switch ch := line[idx]; {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '#' && (idx == 0 || line[idx-1] == ' ' || line[idx-1] == '\t'):
+			return line[:idx]
+		}
+	}
+	return line
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// block parses the mapping or sequence whose lines start at the indent
+func (p *yamlParser) block(indent int) (any, error) {
+	if isSequenceItem(p.lines[p.pos].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func (p *yamlParser) sequence(indent int) (any, error) {
+	items := []any{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		rest := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+		if rest == "" {
+			p.pos++
+			item, err := p.nested(indent, line)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			continue
+		}
+		// a mapping can start on the line of the item, its keys are
+		// indented like the first one
+		if _, _, ok := splitYAMLKey(rest); ok && !strings.HasPrefix(rest, "[") && !strings.HasPrefix(rest, "{") {
+			itemIndent := line.indent + len(line.text) - len(rest)
+			p.lines[p.pos] = yamlLine{number: line.number, indent: itemIndent, text: rest}
+			item, err := p.mapping(itemIndent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			continue
+		}
+		p.pos++
+		item, err := parseYAMLValue(rest, line.number)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (p *yamlParser) mapping(indent int) (any, error) {
+	values := map[string]any{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		line := p.lines[p.pos]
+		if isSequenceItem(line.text) {
+			return nil, yamlErrorf(line.number, "expected a key, got a list item")
+		}
+		key, rest, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, yamlErrorf(line.number, "expected key: value, got %s", line.text)
+		}
+		if _, ok := values[key]; ok {
+			return nil, yamlErrorf(line.number, "%s is defined twice", key)
+		}
+		p.pos++
+		if rest != "" {
+			value, err := parseYAMLValue(rest, line.number)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = value
+			continue
+		}
+		// the items of a sequence may have the indent of its key
+		if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+			value, err := p.sequence(indent)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = value
+			continue
+		}
+		value, err := p.nested(indent, line)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// nested parses the block indented below the line, null if there is none
+func (p *yamlParser) nested(indent int, line yamlLine) (any, error) {
+	if p.pos == len(p.lines) || p.lines[p.pos].indent <= indent {
+		return nil, nil
+	}
+	if p.lines[p.pos].number == line.number {
+		return nil, yamlErrorf(line.number, "unexpected indentation")
+	}
+	return p.block(p.lines[p.pos].indent)
+}
+
+// splitYAMLKey splits key: value, the key may be quoted
+func splitYAMLKey(text string) (string, string, bool) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := strings.IndexByte(text[1:], text[0])
+		if end < 0 {
+			return "", "", false
+		}
+		key, rest := text[1:end+1], text[end+2:]
+		if rest != ":" && !strings.HasPrefix(rest, ": ") {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(rest[1:]), true
+	}
+	key, rest, ok := strings.Cut(text, ": ")
+	if !ok {
+		key, ok = strings.CutSuffix(text, ":")
+	}
+	if !ok || key == "" || strings.ContainsAny(key, "[]{},") {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(rest), true
+}
+
+// parseYAMLValue parses a scalar or a flow collection that fills the rest
+// of a line
+func parseYAMLValue(text string, number int) (any, error) {
+	if strings.HasPrefix(text, "|") || strings.HasPrefix(text, ">") {
+		return nil, yamlErrorf(number, "block scalars are not supported")
+	}
+	if strings.HasPrefix(text, "&") || strings.HasPrefix(text, "*") || strings.HasPrefix(text, "!") {
+		return nil, yamlErrorf(number, "anchors, aliases and tags are not supported")
+	}
+	flow := yamlFlow{text: text, number: number}
+	value, err := flow.value()
+	if err != nil {
+		return nil, err
+	}
+	flow.skipSpaces()
+	if flow.pos < len(text) {
+		return nil, yamlErrorf(number, "unexpected %s", text[flow.pos:])
+	}
+	return value, nil
+}
+
+// yamlFlow reads flow collections like [2, always, [api, cli]]
+type yamlFlow struct {
+	text   string
+	pos    int
+	number int
+	// depth counts the open collections
+	depth int
+}
+
+func (f *yamlFlow) skipSpaces() {
+	for f.pos < len(f.text) && f.text[f.pos] == ' ' {
+		f.pos++
+	}
+}
+
+func (f *yamlFlow) value() (any, error) {
+	f.skipSpaces()
+	if f.pos == len(f.text) {
+		return nil, nil
+	}
+	switch f.text[f.pos] {
+	case '[':
+		return f.collection(']')
+	case '{':
+		return f.collection('}')
+	case '"', '\'':
+		return f.quoted()
+	}
+	// inside a collection a plain scalar ends at a separator, at the top
+	// level it fills the line
+	start := f.pos
+	for f.pos < len(f.text) && !(f.depth > 0 && f.endsPlain()) {
+		f.pos++
+	}
+	return plainYAMLScalar(strings.TrimSpace(f.text[start:f.pos])), nil
+}
+
+// endsPlain reports whether a plain scalar inside of a collection ends at
+// the position
+func (f *yamlFlow) endsPlain() bool {
+	switch f.text[f.pos] {
+	case ',', ']', '}':
+		return true
+	case ':':
+		return f.pos+1 == len(f.text) || f.text[f.pos+1] == ' '
+	}
+	return false
+}
+
+func (f *yamlFlow) collection(end byte) (any, error) {
+	number := f.number
+	f.depth++
+	defer func() { f.depth-- }()
+	f.pos++
+	items := []any{}
+	values := map[string]any{}
+	for {
+		f.skipSpaces()
+		if f.pos == len(f.text) {
+			return nil, yamlErrorf(number, "missing %c", end)
+		}
+		if f.text[f.pos] == end {
+			f.pos++
+			break
+		}
+		item, err := f.value()
+		if err != nil {
+			return nil, err
+		}
+		f.skipSpaces()
+		if end == '}' {
+			key, ok := item.(string)
+			if !ok || f.pos == len(f.text) || f.text[f.pos] != ':' {
+				return nil, yamlErrorf(number, "expected key: value in a mapping")
+			}
+			f.pos++
+			if values[key], err = f.value(); err != nil {
+				return nil, err
+			}
+			f.skipSpaces()
+		} else {
+			items = append(items, item)
+		}
+		if f.pos < len(f.text) && f.text[f.pos] == ',' {
+			f.pos++
+		} else if f.pos < len(f.text) && f.text[f.pos] != end {
+			return nil, yamlErrorf(number, "expected , or %c", end)
+		}
+	}
+	if end == '}' {
+		return values, nil
+	}
+	return items, nil
+}
+
+func (f *yamlFlow) quoted() (any, error) {
+	quote := f.text[f.pos]
+	line := f.number
+	var value strings.Builder
+	for idx := f.pos + 1; idx < len(f.text); idx++ {
+		ch := f.text[idx]
+		switch {
+		case quote == '\'' && ch == '\'' && idx+1 < len(f.text) && f.text[idx+1] == '\'':
+			value.WriteByte('\'')
+			idx++
+		case quote == '"' && ch == '\\' && idx+1 < len(f.text):
+			value.WriteByte(ch)
+			value.WriteByte(f.text[idx+1])
+			idx++
+		case ch == quote:
+			f.pos = idx + 1
+			if quote == '\'' {
+				return value.String(), nil
+			}
+			unquoted, err := strconv.Unquote(`"` + value.String() + `"`)
+			if err != nil {
+				return nil, yamlErrorf(line, "%s", err)
+			}
+			return unquoted, nil
+		default:
+			value.WriteByte(ch)
+		}
+	}
+	return nil, yamlErrorf(line, "missing %c", quote)
+}
+
+// plainYAMLScalar returns the value of an unquoted scalar
+func plainYAMLScalar(text string) any {
+	switch text {
+	case "", "~", "null":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if number, err := strconv.Atoi(text); err == nil {
+		return number
+	}
+	if number, err := strconv.ParseFloat(text, 64); err == nil {
+		return number
+	}
+	return text
+}
@@ -0,0 +1,69 @@
+package config
+
+import "testing"
+
+func TestYAMLToJSON(t *testing.T) {
+	cases := []struct {
+		content  string
+		expected string
+	}{
+		{"", `{}`},
+		{"# only a comment\n", `{}`},
+		{"preset: strict # the team default\n", `{"preset":"strict"}`},
+		{"format:\n  wrapColumn: 80\n", `{"format":{"wrapColumn":80}}`},
+		{"rules:\n  scope-enum: [2, always, [api, 'cli tool', \"docs\"]]\n", `{"rules":{"scope-enum":[2,"always",["api","cli tool","docs"]]}}`},
+		{"scopes:\n  providers:\n  - go\n  - history\n", `{"scopes":{"providers":["go","history"]}}`},
+		{"packages:\n  - name: billing\n    path: services/billing\n  - name: auth\n", `{"packages":[{"name":"billing","path":"services/billing"},{"name":"auth"}]}`},
+		{"rules:\n  header-max-length:\n    - 1\n    - always\n    - 100\n", `{"rules":{"header-max-length":[1,"always",100]}}`},
+		{"\"quoted: key\": {a: 1, b: [true, null]}\n", `{"quoted: key":{"a":1,"b":[true,null]}}`},
+		{"url: http://example.com/#anchor\n", `{"url":"http://example.com/#anchor"}`},
+		{"empty:\nnext: 1\n", `{"empty":null,"next":1}`},
+	}
+
+	for idx, tc := range cases {
+		actual, err := yamlToJSON([]byte(tc.content))
+		if err != nil || string(actual) != tc.expected {
+			t.Fatalf("Test case %d failed. Got %s %v - Exp %s", idx, actual, err, tc.expected)
+		}
+	}
+
+	invalid := []string{
+		"rules: [2, always\n",
+		"a: 1\na: 2\n",
+		"a: 1\n  b: 2\n",
+		"message: |\n  text\n",
+		"base: &base 1\n",
+		"just text\n",
+		"a:\n\tb: 1\n",
+	}
+	for idx, content := range invalid {
+		if actual, err := yamlToJSON([]byte(content)); err == nil {
+			t.Fatalf("Test case %d failed. Got %s - Exp an error", idx, actual)
+		}
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		level   Level
+		valid   bool
+	}{
+		{YAMLFile, "rules:\n  header-max-length: [2, always, 50]\n", Error, true},
+		{YAMLFile, "rules:\n  header-max-length: [3]\n", Disabled, false},
+		{CommitlintFile, `{"extends": ["@commitlint/config-conventional"], "rules": {"type-enum": [2, "always", ["feat"]], "header-max-length": [2, "always", 50]}}`, Error, true},
+		{CommitlintFile, `{"rules": {"header-max-length": [2, "always", "long"]}}`, Disabled, false},
+		{File, `{"rules": {"header-max-length": [2, "always", 50]}}`, Error, true},
+	}
+
+	for idx, tc := range cases {
+		config, err := ParseFile(tc.name, []byte(tc.content))
+		if (err == nil) != tc.valid {
+			t.Fatalf("Test case %d failed. Got error %v", idx, err)
+		}
+		if rule := config.Rules[HeaderMaxLength]; rule.Level != tc.level {
+			t.Fatalf("Test case %d failed. Got %d - Exp %d", idx, rule.Level, tc.level)
+		}
+	}
+}
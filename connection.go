@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cc-lsp/lsp"
+	"cc-lsp/rpc"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// the time the client has to answer a request of the server
+const (
+	requestTimeout = 10 * time.Second
+	// the user has to pick an action of a message
+	userTimeout = 2 * time.Minute
+)
+
+// connection is the connection to the language client. The requests the
+// server sends are matched with the responses of the client by their id,
+// writes are serialized so responses can be written from any goroutine.
+//
+// The messages of the client are handled one after another in the handler
+// loop, which must never wait for a response: the reader would be stuck
+// behind it. Requests of the server are awaited in their own goroutine and
+// post their result back to the loop.
+type connection struct {
+	mu      sync.Mutex
+	writer  io.Writer
+	lastID  int
+	pending map[int]chan lsp.ResponseMessage
+	inbox   *queue
+	// tokens counts the progress tokens, progress can be created from
+	// several goroutines
+	tokens int
+}
+
+func newConnection(writer io.Writer) *connection {
+	return &connection{writer: writer, pending: map[int]chan lsp.ResponseMessage{}, inbox: newQueue()}
+}
+
+// message is a request or a notification of the client, or a callback that
+// carries the result of a request of the server back to the handler loop
+type message struct {
+	method   string
+	contents []byte
+	callback func()
+}
+
+// queue is an unbounded queue of messages, pushing never blocks so the
+// reader always gets to the responses of the client
+type queue struct {
+	mu       sync.Mutex
+	ready    *sync.Cond
+	messages []message
+	closed   bool
+}
+
+func newQueue() *queue {
+	q := &queue{}
+	q.ready = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *queue) push(msg message) {
+	q.mu.Lock()
+	q.messages = append(q.messages, msg)
+	q.mu.Unlock()
+	q.ready.Signal()
+}
+
+func (q *queue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.ready.Broadcast()
+}
+
+// pop waits for the next message, ok is false once the queue is closed and
+// empty
+func (q *queue) pop() (message, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.messages) == 0 && !q.closed {
+		q.ready.Wait()
+	}
+	if len(q.messages) == 0 {
+		return message{}, false
+	}
+	msg := q.messages[0]
+	q.messages = q.messages[1:]
+	return msg, true
+}
+
+// read reads the messages of the client until the input ends, responses are
+// delivered right away and everything else is queued for the handler loop
+func (c *connection) read(logger *log.Logger, scanner *bufio.Scanner) {
+	defer c.inbox.close()
+	for scanner.Scan() {
+		msg := scanner.Bytes()
+		method, contents, err := rpc.DecodeMessage(msg)
+		if err != nil {
+			logger.Printf("Got an error: %s", err)
+			continue
+		}
+
+		// a message without a method answers a request of the server
+		if method == "" {
+			if err := c.handleResponse(contents); err != nil {
+				logger.Printf("Got an error: %s", err)
+			}
+			continue
+		}
+
+		// the scanner reuses its buffer for the next message
+		c.inbox.push(message{method: method, contents: bytes.Clone(contents)})
+	}
+}
+
+// next returns the next message for the handler loop
+func (c *connection) next() (message, bool) {
+	return c.inbox.pop()
+}
+
+// post runs the callback on the handler loop, goroutines that waited for the
+// client use it to get back to the state
+func (c *connection) post(callback func()) {
+	c.inbox.push(message{callback: callback})
+}
+
+func (c *connection) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.writer.Write(p)
+}
+
+// outgoingRequest is a request of the server to the client
+type outgoingRequest struct {
+	lsp.Request
+	Params any `json:"params"`
+}
+
+// request sends a request to the client, the response is delivered on the
+// returned channel once the client answers
+func (c *connection) request(method string, params any) (int, <-chan lsp.ResponseMessage) {
+	c.mu.Lock()
+	c.lastID++
+	id := c.lastID
+	response := make(chan lsp.ResponseMessage, 1)
+	c.pending[id] = response
+	c.mu.Unlock()
+
+	writeResponse(c, outgoingRequest{
+		Request: lsp.Request{RPC: "2.0", ID: id, Method: method},
+		Params:  params,
+	})
+	return id, response
+}
+
+// forget stops waiting for the response of a request
+func (c *connection) forget(id int) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+// handleResponse delivers a response of the client to the request it
+// answers, responses nobody waits for are dropped
+func (c *connection) handleResponse(contents []byte) error {
+	var response lsp.ResponseMessage
+	if err := json.Unmarshal(contents, &response); err != nil {
+		return err
+	}
+	if response.ID == nil {
+		return nil
+	}
+	c.mu.Lock()
+	pending, ok := c.pending[*response.ID]
+	delete(c.pending, *response.ID)
+	c.mu.Unlock()
+	if ok {
+		pending <- response
+	}
+	return nil
+}
+
+// call sends a request to the client and waits for the result. It must not
+// run on the handler loop, see connection.
+func call[T any](c *connection, method string, params any, timeout time.Duration) (T, error) {
+	var result T
+	id, response := c.request(method, params)
+	select {
+	case answer := <-response:
+		if answer.Error != nil {
+			return result, fmt.Errorf("%s: %w", method, answer.Error)
+		}
+		if len(answer.Result) == 0 || string(answer.Result) == "null" {
+			return result, nil
+		}
+		if err := json.Unmarshal(answer.Result, &result); err != nil {
+			return result, fmt.Errorf("%s: %w", method, err)
+		}
+		return result, nil
+	case <-time.After(timeout):
+		c.forget(id)
+		return result, fmt.Errorf("%s: the client did not answer within %s", method, timeout)
+	}
+}
+
+// configuration asks the client for the settings of the sections
+func (c *connection) configuration(items ...lsp.ConfigurationItem) ([]json.RawMessage, error) {
+	return call[[]json.RawMessage](c, "workspace/configuration", lsp.ConfigurationParams{Items: items}, requestTimeout)
+}
+
+// applyEdit asks the client to apply the edit
+func (c *connection) applyEdit(label string, edit lsp.WorkspaceEdit) (lsp.ApplyWorkspaceEditResult, error) {
+	params := lsp.ApplyWorkspaceEditParams{Label: label, Edit: edit}
+	return call[lsp.ApplyWorkspaceEditResult](c, "workspace/applyEdit", params, requestTimeout)
+}
+
+// showMessageRequest shows a message with actions, the action is nil if the
+// user dismissed the message
+func (c *connection) showMessageRequest(typ int, message string, actions ...string) (*lsp.MessageActionItem, error) {
+	params := lsp.ShowMessageRequestParams{Type: typ, Message: message}
+	for _, action := range actions {
+		params.Actions = append(params.Actions, lsp.MessageActionItem{Title: action})
+	}
+	return call[*lsp.MessageActionItem](c, "window/showMessageRequest", params, userTimeout)
+}
+
+// showDocument asks the client to show the document
+func (c *connection) showDocument(uri string) (lsp.ShowDocumentResult, error) {
+	params := lsp.ShowDocumentParams{URI: uri, TakeFocus: true}
+	return call[lsp.ShowDocumentResult](c, "window/showDocument", params, requestTimeout)
+}
+
+// registerCapability registers a capability the server did not announce on
+// initialize, e.g. watched files
+func (c *connection) registerCapability(registrations ...lsp.Registration) error {
+	params := lsp.RegistrationParams{Registrations: registrations}
+	_, err := call[any](c, "client/registerCapability", params, requestTimeout)
+	return err
+}
+
+// progress reports a long running task of the server, it is a no-op if the
+// client did not create the token
+type progress struct {
+	client *connection
+	token  string
+}
+
+// createProgress asks the client for a progress token and reports the begin
+// of the task, the progress is a no-op if the client refused it
+func (c *connection) createProgress(title string) progress {
+	c.mu.Lock()
+	c.tokens++
+	token := fmt.Sprintf("cc-lsp-%d", c.tokens)
+	c.mu.Unlock()
+	if _, err := call[any](c, "window/workDoneProgress/create", lsp.WorkDoneProgressCreateParams{Token: token}, requestTimeout); err != nil {
+		return progress{}
+	}
+	p := progress{client: c, token: token}
+	p.report(lsp.WorkDoneProgressBegin{Kind: "begin", Title: title})
+	return p
+}
+
+func (p progress) report(value any) {
+	if p.client == nil {
+		return
+	}
+	writeResponse(p.client, lsp.ProgressNotification{
+		Notification: lsp.Notification{RPC: "2.0", Method: "$/progress"},
+		Params:       lsp.ProgressParams{Token: p.token, Value: value},
+	})
+}
+
+// end reports the end of the task
+func (p progress) end(message string) {
+	p.report(lsp.WorkDoneProgressEnd{Kind: "end", Message: message})
+}
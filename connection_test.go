@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"cc-lsp/lsp"
+	"cc-lsp/rpc"
+	"encoding/json"
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+// pipeClient is the client side of a connection, it receives the requests
+// of the server and writes its messages to the reader of the connection
+type pipeClient struct {
+	requests <-chan lsp.Request
+	writer   *io.PipeWriter
+}
+
+func newPipeClient(t *testing.T) (*connection, pipeClient) {
+	// the server reads what the editor writes and the other way around
+	serverIn, editorOut := io.Pipe()
+	editorIn, serverOut := io.Pipe()
+	t.Cleanup(func() {
+		editorOut.Close()
+		serverOut.Close()
+	})
+
+	client := newConnection(serverOut)
+	scanner := bufio.NewScanner(serverIn)
+	scanner.Split(rpc.Split)
+	go client.read(log.New(io.Discard, "", 0), scanner)
+
+	requests := make(chan lsp.Request, 10)
+	go func() {
+		scanner := bufio.NewScanner(editorIn)
+		scanner.Split(rpc.Split)
+		for scanner.Scan() {
+			_, contents, err := rpc.DecodeMessage(scanner.Bytes())
+			var request lsp.Request
+			if err == nil && json.Unmarshal(contents, &request) == nil {
+				requests <- request
+			}
+		}
+	}()
+	return client, pipeClient{requests: requests, writer: editorOut}
+}
+
+func (c pipeClient) send(t *testing.T, msg any) {
+	if _, err := io.WriteString(c.writer, rpc.EncodeMessage(msg)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCallWhileMessagesArrive(t *testing.T) {
+	client, editor := newPipeClient(t)
+
+	// the editor sends notifications before it answers, the reader must
+	// not wait for the handler loop to get to the response
+	go func() {
+		request := <-editor.requests
+		for range 3 {
+			editor.send(t, lsp.Notification{RPC: "2.0", Method: "textDocument/didChange"})
+		}
+		editor.send(t, map[string]any{"jsonrpc": "2.0", "id": request.ID, "result": map[string]any{"applied": true}})
+	}()
+
+	applied, err := client.applyEdit("edit", lsp.WorkspaceEdit{})
+	if err != nil || !applied.Applied {
+		t.Fatalf("the edit should be applied, got %+v %v", applied, err)
+	}
+	for range 3 {
+		if msg, ok := client.next(); !ok || msg.method != "textDocument/didChange" {
+			t.Fatalf("the notifications should be queued for the handler loop, got %+v", msg)
+		}
+	}
+}
+
+func TestCallErrors(t *testing.T) {
+	client, editor := newPipeClient(t)
+
+	cases := []struct {
+		answer  func(id int) any
+		timeout time.Duration
+	}{
+		{func(int) any { return nil }, 20 * time.Millisecond},
+		{func(id int) any {
+			return map[string]any{"jsonrpc": "2.0", "id": id, "error": map[string]any{"code": -32601, "message": "unknown method"}}
+		}, time.Second},
+		{func(id int) any { return map[string]any{"jsonrpc": "2.0", "id": id, "result": "not a result"} }, time.Second},
+	}
+
+	for idx, tc := range cases {
+		go func() {
+			request := <-editor.requests
+			if answer := tc.answer(request.ID); answer != nil {
+				editor.send(t, answer)
+			}
+		}()
+		if _, err := call[lsp.ApplyWorkspaceEditResult](client, "workspace/applyEdit", nil, tc.timeout); err == nil {
+			t.Fatalf("Test case %d failed. Got no error", idx)
+		}
+		client.mu.Lock()
+		pending := len(client.pending)
+		client.mu.Unlock()
+		if pending != 0 {
+			t.Fatalf("Test case %d failed. Got %d pending requests - Exp 0", idx, pending)
+		}
+	}
+}
+
+func TestPost(t *testing.T) {
+	client, editor := newPipeClient(t)
+	editor.send(t, lsp.Notification{RPC: "2.0", Method: "initialized"})
+	if msg, _ := client.next(); msg.method != "initialized" {
+		t.Fatalf("Got %+v", msg)
+	}
+
+	ran := false
+	client.post(func() { ran = true })
+	if msg, ok := client.next(); !ok || msg.callback == nil {
+		t.Fatalf("the callback should be queued, got %+v", msg)
+	} else {
+		msg.callback()
+	}
+	if !ran {
+		t.Fatal("the callback should run on the loop")
+	}
+
+	editor.writer.Close()
+	if _, ok := client.next(); ok {
+		t.Fatal("the queue should be closed once the input ends")
+	}
+}
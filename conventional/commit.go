@@ -0,0 +1,297 @@
+package conventional
+
+import (
+	"cc-lsp/lsp"
+	"regexp"
+	"strings"
+)
+
+// ScissorsLine marks the start of the diff that `git commit -v` appends to
+// the message, everything below it is ignored by git
+const ScissorsLine = "# ------------------------ >8 ------------------------"
+
+// headerRegexp matches the header of a conventional commit and captures the
+// type, the scope, the breaking change marker and the description
+var headerRegexp = buildHeaderRegexp(lsp.Prefixes)
+
+// shapeRegexp matches a header with the shape of a conventional commit but
+// any type, it only gives the positions of unknown types
+var shapeRegexp = regexp.MustCompile(`^([A-Za-z]+)(?:\((.+?)\))?(!)?:\s+(.*)$`)
+
+// referenceRegexp matches issue references like #12, owner/repo#12 and GH-12
+var referenceRegexp = regexp.MustCompile(`(?:[\w.-]+/[\w.-]+)?#\d+|\bGH-\d+\b`)
+
+// footerRegexp matches a git trailer like footer line and captures the token
+// and the value
+var footerRegexp = regexp.MustCompile(`^(BREAKING CHANGE|BREAKING-CHANGE|[A-Za-z][\w-]*)(?:: | #)(.*)$`)
+
+func buildHeaderRegexp(prefixes []string) *regexp.Regexp {
+	quoted := make([]string, 0, len(prefixes))
+	for _, item := range prefixes {
+		quoted = append(quoted, regexp.QuoteMeta(item))
+	}
+	return regexp.MustCompile(`^(` + strings.Join(quoted, "|") + `)(?:\((.+?)\))?(!)?:\s+(.*)$`)
+}
+
+// Commit is a commit message split into the parts of a conventional commit
+type Commit struct {
+	// Conventional is false if the header does not follow the conventional
+	// commit format, only Description is set in that case
+	Conventional bool
+	Type         string
+	Scope        string
+	// Breaking is true if the header has a `!` or a BREAKING CHANGE footer exists
+	Breaking    bool
+	Description string
+	Body        string
+	// BodyRange is the position of the body, only meaningful if Body is set
+	BodyRange lsp.Range
+	Footers   []Footer
+	// Header is the position of the header in the parsed text, nil if the
+	// message has no header
+	Header *Header
+	// Comments are the lines of the comments above the scissors line
+	Comments []int
+	// Scissors is the line of the scissors, -1 if the message has none
+	Scissors int
+	// References are the positions of issue references like #12
+	References []lsp.Range
+	// Diff holds the files of the diff below the scissors line
+	Diff []DiffFile
+}
+
+// Header holds the positions of the parts of the header line, the ranges of
+// a missing scope or breaking change marker are empty
+type Header struct {
+	Line        int
+	Type        lsp.Range
+	Scope       lsp.Range
+	Breaking    lsp.Range
+	Description lsp.Range
+	// UnknownType is set if the header has the shape of a conventional commit
+	// but the type is not known, the ranges are set but the commit is not
+	// conventional
+	UnknownType bool
+}
+
+// Footer is a single `Token: value` or `Token #value` footer of a commit
+type Footer struct {
+	Token string
+	Value string
+	// Line is the line of the token in the parsed text
+	Line int
+	// Separator is either ": " or " #"
+	Separator  string
+	TokenRange lsp.Range
+	// ValueRanges holds a range for every line of the value
+	ValueRanges []lsp.Range
+}
+
+// IsBreakingChange reports whether the footer announces a breaking change
+func (f Footer) IsBreakingChange() bool {
+	return f.Token == "BREAKING CHANGE" || f.Token == "BREAKING-CHANGE"
+}
+
+// Contains reports whether the position is inside the range, the end of the
+// range is exclusive
+func Contains(r lsp.Range, position lsp.Position) bool {
+	if position.Line < r.Start.Line || position.Line > r.End.Line {
+		return false
+	}
+	if position.Line == r.Start.Line && position.Character < r.Start.Character {
+		return false
+	}
+	if position.Line == r.End.Line && position.Character >= r.End.Character {
+		return false
+	}
+	return true
+}
+
+// line is a line of the message and its number in the parsed text
+type line struct {
+	number int
+	text   string
+}
+
+// messageLines returns the lines of a commit message the way git sees them:
+// comment lines are dropped and everything below the scissors line is cut
+// off. The line numbers of the comments and the scissors are returned as well.
+func messageLines(text string) ([]line, []int, int) {
+	lines := []line{}
+	comments := []int{}
+	for number, text := range strings.Split(text, "\n") {
+		text = strings.TrimRight(text, "\r")
+		if text == ScissorsLine {
+			return lines, comments, number
+		}
+		if strings.HasPrefix(text, "#") {
+			comments = append(comments, number)
+			continue
+		}
+		lines = append(lines, line{number: number, text: text})
+	}
+	return lines, comments, -1
+}
+
+func isBlank(l line) bool {
+	return strings.TrimSpace(l.text) == ""
+}
+
+// Parse parses a commit message, either the raw content of the
+// COMMIT_EDITMSG file or a message taken from the git log
+func Parse(text string) Commit {
+	lines, comments, scissors := messageLines(text)
+	diff := []DiffFile{}
+	if scissors >= 0 {
+		all := strings.Split(text, "\n")
+		for idx := range all {
+			all[idx] = strings.TrimRight(all[idx], "\r")
+		}
+		diff = parseDiff(all, scissors)
+	}
+
+	// skip the empty lines before the header
+	for len(lines) > 0 && isBlank(lines[0]) {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return Commit{Comments: comments, Scissors: scissors, Diff: diff}
+	}
+
+	commit := parseHeader(lines[0])
+	commit.Comments = comments
+	commit.Scissors = scissors
+	commit.Diff = diff
+	commit.References = findReferences(lines)
+	rest := lines[1:]
+
+	// the footers are the last paragraph if every line of it starts with a token
+	paragraphStart := len(rest)
+	for paragraphStart > 0 && isBlank(rest[paragraphStart-1]) {
+		paragraphStart--
+	}
+	end := paragraphStart
+	for paragraphStart > 0 && !isBlank(rest[paragraphStart-1]) {
+		paragraphStart--
+	}
+	if paragraphStart < end && footerRegexp.MatchString(rest[paragraphStart].text) {
+		commit.Footers = parseFooters(rest[paragraphStart:end])
+		rest = rest[:paragraphStart]
+	}
+
+	body := make([]string, 0, len(rest))
+	for _, l := range rest {
+		body = append(body, l.text)
+	}
+	commit.Body = strings.TrimSpace(strings.Join(body, "\n"))
+	for len(rest) > 0 && isBlank(rest[0]) {
+		rest = rest[1:]
+	}
+	for len(rest) > 0 && isBlank(rest[len(rest)-1]) {
+		rest = rest[:len(rest)-1]
+	}
+	if len(rest) > 0 {
+		last := rest[len(rest)-1]
+		commit.BodyRange = lsp.Range{
+			Start: lsp.Position{Line: rest[0].number, Character: 0},
+			End:   lsp.Position{Line: last.number, Character: len(last.text)},
+		}
+	}
+
+	for _, footer := range commit.Footers {
+		if footer.IsBreakingChange() {
+			commit.Breaking = true
+		}
+	}
+	return commit
+}
+
+func parseHeader(l line) Commit {
+	header := &Header{Line: l.number}
+	match := headerRegexp.FindStringSubmatchIndex(l.text)
+	if match == nil {
+		header.Description = lineRange(l.number, 0, len(l.text))
+		commit := Commit{Description: strings.TrimSpace(l.text), Header: header}
+		if shape := shapeRegexp.FindStringSubmatchIndex(l.text); shape != nil {
+			match = shape
+			header.UnknownType = true
+		} else {
+			return commit
+		}
+	}
+
+	// the submatch indices are pairs of start and end, -1 for missing groups
+	span := func(group int) lsp.Range {
+		start, end := match[2*group], match[2*group+1]
+		if start < 0 {
+			return lineRange(l.number, 0, 0)
+		}
+		return lineRange(l.number, start, end)
+	}
+	header.Type = span(1)
+	header.Scope = span(2)
+	header.Breaking = span(3)
+	header.Description = span(4)
+	if header.UnknownType {
+		return Commit{Description: strings.TrimSpace(l.text), Header: header}
+	}
+
+	text := func(group int) string {
+		if match[2*group] < 0 {
+			return ""
+		}
+		return l.text[match[2*group]:match[2*group+1]]
+	}
+	return Commit{
+		Conventional: true,
+		Type:         text(1),
+		Scope:        text(2),
+		Breaking:     text(3) == "!",
+		Description:  text(4),
+		Header:       header,
+	}
+}
+
+// parseFooters parses the footer paragraph, lines that do not start with a
+// token continue the value of the previous footer
+func parseFooters(lines []line) []Footer {
+	footers := []Footer{}
+	for _, l := range lines {
+		match := footerRegexp.FindStringSubmatchIndex(l.text)
+		if match == nil {
+			if len(footers) > 0 {
+				last := &footers[len(footers)-1]
+				last.Value += "\n" + l.text
+				last.ValueRanges = append(last.ValueRanges, lineRange(l.number, 0, len(l.text)))
+			}
+			continue
+		}
+		footers = append(footers, Footer{
+			Token:       l.text[match[2]:match[3]],
+			Value:       l.text[match[4]:match[5]],
+			Line:        l.number,
+			Separator:   l.text[match[3]:match[4]],
+			TokenRange:  lineRange(l.number, match[2], match[3]),
+			ValueRanges: []lsp.Range{lineRange(l.number, match[4], match[5])},
+		})
+	}
+	return footers
+}
+
+// findReferences returns the positions of the issue references in the lines
+func findReferences(lines []line) []lsp.Range {
+	references := []lsp.Range{}
+	for _, l := range lines {
+		for _, match := range referenceRegexp.FindAllStringIndex(l.text, -1) {
+			references = append(references, lineRange(l.number, match[0], match[1]))
+		}
+	}
+	return references
+}
+
+func lineRange(line, start, end int) lsp.Range {
+	return lsp.Range{
+		Start: lsp.Position{Line: line, Character: start},
+		End:   lsp.Position{Line: line, Character: end},
+	}
+}
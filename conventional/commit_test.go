@@ -0,0 +1,103 @@
+package conventional
+
+import (
+	"cc-lsp/lsp"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected Commit
+		footers  int
+	}{
+		{"feat(api): add pagination", Commit{Conventional: true, Type: "feat", Scope: "api", Description: "add pagination"}, 0},
+		{"fix!: drop the old endpoint", Commit{Conventional: true, Type: "fix", Breaking: true, Description: "drop the old endpoint"}, 0},
+		{"# a comment\n\nfix: a bug\n\nsome body\ntext", Commit{Conventional: true, Type: "fix", Description: "a bug", Body: "some body\ntext"}, 0},
+		{"feat: x\n\nbody\n\nBREAKING CHANGE: the config moved\nRefs: #12", Commit{Conventional: true, Type: "feat", Breaking: true, Description: "x", Body: "body"}, 2},
+		{"feat: x\n\nReviewed-by: Z\nRefs #133", Commit{Conventional: true, Type: "feat", Description: "x"}, 2},
+		{"feat: x\n\nthis is: not a footer paragraph", Commit{Conventional: true, Type: "feat", Description: "x", Body: "this is: not a footer paragraph"}, 0},
+		{"update the readme", Commit{Description: "update the readme"}, 0},
+		{"feat: x\n" + ScissorsLine + "\ndiff --git a/x b/x", Commit{Conventional: true, Type: "feat", Description: "x"}, 0},
+		{"", Commit{}, 0},
+	}
+
+	for idx, tc := range cases {
+		commit := Parse(tc.text)
+		if len(commit.Footers) != tc.footers {
+			t.Fatalf("Test case %d failed. Got %d footers - Exp %d", idx, len(commit.Footers), tc.footers)
+		}
+		commit.Footers = nil
+		commit.Header = nil
+		commit.Comments = nil
+		commit.Scissors = 0
+		commit.References = nil
+		commit.BodyRange = lsp.Range{}
+		commit.Diff = nil
+		if !reflect.DeepEqual(commit, tc.expected) {
+			t.Fatalf("Test case %d failed. Got %+v - Exp %+v", idx, commit, tc.expected)
+		}
+	}
+}
+
+func TestParseHeaderPositions(t *testing.T) {
+	commit := Parse("# Please enter the commit message\n\nfeat(api)!: add pagination\n\nRefs: #12")
+	header := commit.Header
+	if header == nil || header.Line != 2 {
+		t.Fatalf("header should be on line 2 is %+v", header)
+	}
+
+	cases := []struct {
+		name     string
+		actual   lsp.Range
+		expected lsp.Range
+	}{
+		{"type", header.Type, lineRange(2, 0, 4)},
+		{"scope", header.Scope, lineRange(2, 5, 8)},
+		{"breaking", header.Breaking, lineRange(2, 9, 10)},
+		{"description", header.Description, lineRange(2, 12, 26)},
+	}
+	for _, tc := range cases {
+		if tc.actual != tc.expected {
+			t.Fatalf("%s: Got %+v - Exp %+v", tc.name, tc.actual, tc.expected)
+		}
+	}
+
+	if len(commit.Footers) != 1 || commit.Footers[0].Line != 4 {
+		t.Fatalf("footer should be on line 4 is %+v", commit.Footers)
+	}
+	if !Contains(header.Breaking, lsp.Position{Line: 2, Character: 9}) || Contains(header.Type, lsp.Position{Line: 2, Character: 4}) {
+		t.Fatal("the end of a range should be exclusive")
+	}
+}
+
+func TestParseTokenPositions(t *testing.T) {
+	commit := Parse("chore(ci): bump go for #3\n\nBREAKING CHANGE: drops go 1.21\n  and 1.22\n# a comment\n" + ScissorsLine + "\ndiff --git a/x b/x")
+	header := commit.Header
+	if commit.Conventional || header == nil || !header.UnknownType {
+		t.Fatalf("chore should be an unknown type is %+v", header)
+	}
+	if header.Type != lineRange(0, 0, 5) || header.Scope != lineRange(0, 6, 8) || header.Description != lineRange(0, 11, 25) {
+		t.Fatalf("unknown types should have positions is %+v", header)
+	}
+
+	if len(commit.Footers) != 1 {
+		t.Fatalf("expected one footer is %+v", commit.Footers)
+	}
+	footer := commit.Footers[0]
+	values := []lsp.Range{lineRange(2, 17, 30), lineRange(3, 0, 10)}
+	if footer.TokenRange != lineRange(2, 0, 15) || !reflect.DeepEqual(footer.ValueRanges, values) {
+		t.Fatalf("Got %+v %+v - Exp %+v", footer.TokenRange, footer.ValueRanges, values)
+	}
+
+	if !reflect.DeepEqual(commit.Comments, []int{4}) || commit.Scissors != 5 {
+		t.Fatalf("Got comments %v scissors %d", commit.Comments, commit.Scissors)
+	}
+	if !reflect.DeepEqual(commit.References, []lsp.Range{lineRange(0, 23, 25)}) {
+		t.Fatalf("Got references %+v", commit.References)
+	}
+	if Parse("fix: x").Scissors != -1 {
+		t.Fatal("a message without scissors should have -1")
+	}
+}
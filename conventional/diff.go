@@ -0,0 +1,59 @@
+package conventional
+
+import (
+	"cc-lsp/lsp"
+	"strings"
+)
+
+// DiffFile is a file of the diff that `git commit -v` appends below the
+// scissors line
+type DiffFile struct {
+	Name  string
+	Range lsp.Range
+	// Hunks are the ranges of the `@@` sections of the file
+	Hunks []lsp.Range
+}
+
+// parseDiff splits the lines below the scissors line into files and hunks
+func parseDiff(lines []string, scissors int) []DiffFile {
+	files := []DiffFile{}
+	// end closes the last section before the line
+	end := func(r *lsp.Range, number int) {
+		for number-1 > r.Start.Line && strings.TrimSpace(lines[number-1]) == "" {
+			number--
+		}
+		*r = lineRange(r.Start.Line, r.Start.Character, 0)
+		r.End = lsp.Position{Line: number - 1, Character: len(lines[number-1])}
+	}
+	closeFile := func(number int) {
+		if len(files) == 0 {
+			return
+		}
+		file := &files[len(files)-1]
+		end(&file.Range, number)
+		if len(file.Hunks) > 0 {
+			end(&file.Hunks[len(file.Hunks)-1], number)
+		}
+	}
+
+	for number := scissors + 1; number < len(lines); number++ {
+		text := lines[number]
+		switch {
+		case strings.HasPrefix(text, "diff --git "):
+			closeFile(number)
+			name := strings.TrimPrefix(text, "diff --git ")
+			if idx := strings.LastIndex(name, " b/"); idx >= 0 {
+				name = name[idx+len(" b/"):]
+			}
+			files = append(files, DiffFile{Name: name, Range: lineRange(number, 0, len(text)), Hunks: []lsp.Range{}})
+		case strings.HasPrefix(text, "@@") && len(files) > 0:
+			file := &files[len(files)-1]
+			if len(file.Hunks) > 0 {
+				end(&file.Hunks[len(file.Hunks)-1], number)
+			}
+			file.Hunks = append(file.Hunks, lineRange(number, 0, len(text)))
+		}
+	}
+	closeFile(len(lines))
+	return files
+}
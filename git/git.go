@@ -0,0 +1,258 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Repo is a local git repository, all commands run in its top level directory
+type Repo struct {
+	Dir string
+}
+
+// Commit is a single commit taken from the git log
+type Commit struct {
+	Hash    string
+	Author  string
+	Date    time.Time
+	Message string
+	// Files are the paths touched by the commit relative to the repository root
+	Files []string
+}
+
+// Open returns the repository that contains the given directory
+func Open(dir string) (Repo, error) {
+	out, err := run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return Repo{}, err
+	}
+	return Repo{Dir: strings.TrimSpace(out)}, nil
+}
+
+// OpenForFile returns the repository a file belongs to, files inside the git
+// directory like .git/COMMIT_EDITMSG belong to the work tree around it
+func OpenForFile(path string) (Repo, error) {
+	dir := filepath.Dir(path)
+	if root, _, found := strings.Cut(filepath.ToSlash(dir)+"/", "/.git/"); found {
+		dir = filepath.FromSlash(root)
+	}
+	return Open(dir)
+}
+
+func run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// Git runs a git command in the repository and returns its output
+func (r Repo) Git(args ...string) (string, error) {
+	return run(r.Dir, args...)
+}
+
+// Tags returns the tags of the repository, with merged set only the tags
+// reachable from HEAD are returned
+func (r Repo) Tags(merged bool) ([]string, error) {
+	args := []string{"tag", "--list"}
+	if merged {
+		args = append(args, "--merged", "HEAD")
+	}
+	out, err := r.Git(args...)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// the separators are ASCII unit and record separators which do not show up
+// in commit messages
+const (
+	fieldSeparator  = "\x1f"
+	recordSeparator = "\x1e"
+	logFormat       = "--format=%x1e%H%x1f%an%x1f%at%x1f%B%x1f"
+)
+
+// Log returns the commits reachable from HEAD that are not reachable from
+// since, newest first; an empty since returns the whole history
+func (r Repo) Log(since string) ([]Commit, error) {
+	args := []string{"-c", "core.quotePath=false", "log", logFormat, "--name-only"}
+	if since != "" {
+		args = append(args, since+"..HEAD")
+	} else {
+		args = append(args, "HEAD")
+	}
+	out, err := r.Git(args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(out)
+}
+
+// Subjects returns the hash, date, author and subject of the commits
+// reachable from HEAD that are not reachable from since, newest first. It is
+// a lot cheaper than Log on large histories.
+func (r Repo) Subjects(since string) ([]Commit, error) {
+	args := []string{"log", "--format=%H%x1f%at%x1f%an%x1f%s"}
+	if since != "" {
+		args = append(args, since+"..HEAD")
+	} else {
+		args = append(args, "HEAD")
+	}
+	out, err := r.Git(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []Commit{}
+	for _, line := range lines(out) {
+		fields := strings.SplitN(line, fieldSeparator, 4)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("unexpected git log line: %q", line)
+		}
+		seconds, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, Commit{Hash: fields[0], Date: time.Unix(seconds, 0), Author: fields[2], Message: fields[3]})
+	}
+	return commits, nil
+}
+
+// StagedFiles returns the paths of the staged files relative to the
+// repository root
+func (r Repo) StagedFiles() ([]string, error) {
+	out, err := r.Git("-c", "core.quotePath=false", "diff", "--cached", "--name-only")
+	if err != nil {
+		return nil, err
+	}
+	return lines(out), nil
+}
+
+// FileStatus is a staged file and how it changed: A for added, M for
+// modified, D for deleted and R for renamed
+type FileStatus struct {
+	Status byte
+	Path   string
+}
+
+// StagedStatus returns the staged files with their status, renamed files
+// have their new path
+func (r Repo) StagedStatus() ([]FileStatus, error) {
+	out, err := r.Git("-c", "core.quotePath=false", "diff", "--cached", "--name-status")
+	if err != nil {
+		return nil, err
+	}
+	files := []FileStatus{}
+	for _, line := range lines(out) {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 || fields[0] == "" {
+			return nil, fmt.Errorf("unexpected git diff line: %q", line)
+		}
+		files = append(files, FileStatus{Status: fields[0][0], Path: fields[len(fields)-1]})
+	}
+	return files, nil
+}
+
+// Config returns the value of a git config key, empty if it is not set
+func (r Repo) Config(key string) string {
+	out, err := r.Git("config", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// LastMessage returns the message of the commit HEAD points to
+func (r Repo) LastMessage() (string, error) {
+	out, err := r.Git("log", "-1", "--format=%B")
+	return strings.TrimSpace(out), err
+}
+
+// Show returns the content of the file at the revision, an empty revision
+// returns the staged content
+func (r Repo) Show(revision, path string) ([]byte, error) {
+	out, err := r.Git("show", revision+":"+path)
+	return []byte(out), err
+}
+
+// Head returns the hash of the commit HEAD points to
+func (r Repo) Head() (string, error) {
+	out, err := r.Git("rev-parse", "--verify", "HEAD")
+	return strings.TrimSpace(out), err
+}
+
+// IsAncestor reports whether the commit is reachable from HEAD
+func (r Repo) IsAncestor(commit string) bool {
+	_, err := r.Git("merge-base", "--is-ancestor", commit, "HEAD")
+	return err == nil
+}
+
+// GitDir returns the absolute path of the git directory of the repository
+func (r Repo) GitDir() (string, error) {
+	out, err := r.Git("rev-parse", "--absolute-git-dir")
+	return strings.TrimSpace(out), err
+}
+
+func parseLog(out string) ([]Commit, error) {
+	commits := []Commit{}
+	for _, record := range strings.Split(out, recordSeparator) {
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, fieldSeparator, 5)
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("unexpected git log record: %q", record)
+		}
+		seconds, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, Commit{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Date:    time.Unix(seconds, 0),
+			Message: strings.TrimSpace(fields[3]),
+			Files:   lines(fields[4]),
+		})
+	}
+	return commits, nil
+}
+
+// CommitFiles commits only the given files, other staged changes are left
+// untouched
+func (r Repo) CommitFiles(message string, files ...string) error {
+	if _, err := r.Git(append([]string{"add", "--"}, files...)...); err != nil {
+		return err
+	}
+	_, err := r.Git(append([]string{"commit", "--message", message, "--"}, files...)...)
+	return err
+}
+
+// CreateTag creates an annotated tag on HEAD, the message is kept verbatim so
+// markdown headings are not dropped as comments
+func (r Repo) CreateTag(name, message string) error {
+	_, err := r.Git("tag", "--annotate", "--cleanup=verbatim", "--message", message, name)
+	return err
+}
+
+// lines returns the non empty lines of the output
+func lines(out string) []string {
+	result := []string{}
+	for _, line := range strings.Split(out, "\n") {
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
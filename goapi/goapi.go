@@ -0,0 +1,164 @@
+package goapi
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// API maps the exported identifiers of a package to their signature:
+// functions (Name), methods (Type.Name), types (Type), struct fields
+// (Type.Field), constants and variables (Name)
+type API map[string]string
+
+// Change is an exported identifier that was removed or whose signature changed
+type Change struct {
+	Name    string
+	Removed bool
+	// Old and New are the signatures
+	Old string
+	New string
+}
+
+func (c Change) String() string {
+	if c.Removed {
+		return "removed " + c.Name
+	}
+	return "changed " + c.Name + " from " + c.Old + " to " + c.New
+}
+
+// Parse adds the exported API of a Go source file to the API and returns
+// the package name
+func (a API) Parse(filename string, src []byte) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+	if err != nil {
+		return "", err
+	}
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			a.addFunc(fset, decl)
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				a.addSpec(fset, spec)
+			}
+		}
+	}
+	return file.Name.Name, nil
+}
+
+func (a API) addFunc(fset *token.FileSet, decl *ast.FuncDecl) {
+	if !decl.Name.IsExported() {
+		return
+	}
+	name := decl.Name.Name
+	if decl.Recv != nil && len(decl.Recv.List) > 0 {
+		receiver := receiverName(decl.Recv.List[0].Type)
+		// methods of unexported types are not part of the API
+		if !ast.IsExported(receiver) {
+			return
+		}
+		name = receiver + "." + name
+	}
+	a[name] = signature(fset, decl.Type)
+}
+
+func (a API) addSpec(fset *token.FileSet, spec ast.Spec) {
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		if !spec.Name.IsExported() {
+			return
+		}
+		name := spec.Name.Name
+		structType, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			definition := " "
+			if spec.Assign.IsValid() {
+				definition = " = "
+			}
+			a[name] = "type " + name + definition + typeString(fset, spec.Type)
+			return
+		}
+		a[name] = "type " + name + " struct"
+		for _, field := range structType.Fields.List {
+			for _, fieldName := range field.Names {
+				if fieldName.IsExported() {
+					a[name+"."+fieldName.Name] = typeString(fset, field.Type)
+				}
+			}
+		}
+	case *ast.ValueSpec:
+		for _, valueName := range spec.Names {
+			if valueName.IsExported() {
+				a[valueName.Name] = typeString(fset, spec.Type)
+			}
+		}
+	}
+}
+
+// receiverName returns the name of the type of a method receiver
+func receiverName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(expr.X)
+	case *ast.IndexExpr:
+		return receiverName(expr.X)
+	case *ast.IndexListExpr:
+		return receiverName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	}
+	return ""
+}
+
+func typeString(fset *token.FileSet, expr ast.Expr) string {
+	if expr == nil {
+		return ""
+	}
+	var b bytes.Buffer
+	printer.Fprint(&b, fset, expr)
+	return b.String()
+}
+
+// signature prints the types of the parameters and results, renaming a
+// parameter does not change the API
+func signature(fset *token.FileSet, funcType *ast.FuncType) string {
+	return "func(" + strings.Join(fieldTypes(fset, funcType.Params), ", ") + ") (" +
+		strings.Join(fieldTypes(fset, funcType.Results), ", ") + ")"
+}
+
+func fieldTypes(fset *token.FileSet, fields *ast.FieldList) []string {
+	types := []string{}
+	if fields == nil {
+		return types
+	}
+	for _, field := range fields.List {
+		// a, b int are two parameters of the same type
+		for i := 0; i < max(len(field.Names), 1); i++ {
+			types = append(types, typeString(fset, field.Type))
+		}
+	}
+	return types
+}
+
+// Diff returns the identifiers of the old API that are missing or have a
+// different signature in the new API, sorted by name
+func Diff(old, new API) []Change {
+	changes := []Change{}
+	for name, oldSignature := range old {
+		newSignature, ok := new[name]
+		if !ok {
+			changes = append(changes, Change{Name: name, Removed: true, Old: oldSignature})
+		} else if newSignature != oldSignature {
+			changes = append(changes, Change{Name: name, Old: oldSignature, New: newSignature})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
@@ -0,0 +1,73 @@
+package goapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+const oldSource = `package shop
+
+type Cart struct {
+	Items []Item
+	Total int
+	owner string
+}
+
+type Item string
+
+type cache struct{}
+
+func (c *Cart) Add(item Item, count int) error { return nil }
+func (c cache) Get() {}
+func NewCart(owner, currency string) *Cart { return nil }
+func Checkout(cart *Cart) error { return nil }
+func helper() {}
+
+const Version = "1.0"
+
+var DefaultCurrency string
+`
+
+const newSource = `package shop
+
+type Cart struct {
+	Items []Item
+	Total int64
+	Discount int
+}
+
+type Item = string
+
+func (c *Cart) Add(it Item, n int) error { return nil }
+func NewCart(owner string, currency string) *Cart { return nil }
+func checkout(cart *Cart) error { return nil }
+
+const Version = "1.0"
+
+var DefaultCurrency string
+`
+
+func TestDiff(t *testing.T) {
+	old, new := API{}, API{}
+	if name, err := old.Parse("old.go", []byte(oldSource)); err != nil || name != "shop" {
+		t.Fatalf("the package should be shop, got %s %v", name, err)
+	}
+	if _, err := new.Parse("new.go", []byte(newSource)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Change{
+		{Name: "Cart.Total", Old: "int", New: "int64"},
+		{Name: "Checkout", Removed: true, Old: "func(*Cart) (error)"},
+		{Name: "Item", Old: "type Item string", New: "type Item = string"},
+	}
+	actual := Diff(old, new)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Got %+v - Exp %+v", actual, expected)
+	}
+
+	// renamed parameters, new fields and unexported identifiers do not matter
+	if changes := Diff(new, new); !reflect.DeepEqual(changes, []Change{}) {
+		t.Fatalf("an API should not differ from itself, got %+v", changes)
+	}
+}
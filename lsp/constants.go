@@ -1,31 +1,112 @@
 package lsp
 
-type Content string
-
-// the content of the over response for the different message types
-const (
-	buildContent    Content = "build: Changes that affect the build system or external dependencies (example scopes: gulp, broccoli, npm)"
-	ciContent       Content = "ci: Changes to our CI configuration files and scripts (example scopes: Travis, Circle, BrowserStack, SauceLabs)"
-	docsContent     Content = "docs: Documentation only changes"
-	featContent     Content = "feat: A new feature"
-	fixContent      Content = "fix: A bug fix"
-	perfContent     Content = "perf: A code change that improves performance"
-	refactorContent Content = "refactor: A code change that neither fixes a bug nor adds a feature"
-	styleContent    Content = "style: Changes that do not affect the meaning of the code (white-space, formatting, missing semi-colons, etc)"
-	testContent     Content = "test: Adding missing tests or correcting existing tests"
+import "strings"
+
+// TypeDoc documents a conventional commit type for hovers and completions
+type TypeDoc struct {
+	// Summary is the one line description of the type
+	Summary     string
+	Description string
+	Examples    []string
+}
+
+// the documentation of the different message types
+var (
+	buildDoc = TypeDoc{
+		Summary:     "Changes that affect the build system or external dependencies (example scopes: gulp, broccoli, npm)",
+		Description: "Use it for build scripts, compiler flags, Makefiles, packaging and dependency updates. Changes to the CI pipeline are `ci` instead.",
+		Examples: []string{
+			"build(deps): bump golang.org/x/text to v0.14.0",
+			"build: strip debug symbols from the release binary",
+		},
+	}
+	ciDoc = TypeDoc{
+		Summary:     "Changes to our CI configuration files and scripts (example scopes: Travis, Circle, BrowserStack, SauceLabs)",
+		Description: "Use it for workflow files, pipeline definitions and scripts that only run in CI.",
+		Examples: []string{
+			"ci: run the tests on windows",
+			"ci(release): publish the binaries on every tag",
+		},
+	}
+	docsDoc = TypeDoc{
+		Summary:     "Documentation only changes",
+		Description: "Use it for the readme, guides and code comments. It does not bump the version.",
+		Examples: []string{
+			"docs: explain the release commands in the readme",
+			"docs(api): document the pagination parameters",
+		},
+	}
+	featDoc = TypeDoc{
+		Summary:     "A new feature",
+		Description: "Use it for functionality the users of the project can notice. It bumps the minor version.",
+		Examples: []string{
+			"feat(api): add pagination to the list endpoints",
+			"feat!: require go 1.22",
+		},
+	}
+	fixDoc = TypeDoc{
+		Summary:     "A bug fix",
+		Description: "Use it for changes that make the project behave as intended. It bumps the patch version.",
+		Examples: []string{
+			"fix(parser): handle empty lines before the header",
+			"fix: close the log file on exit",
+		},
+	}
+	perfDoc = TypeDoc{
+		Summary:     "A code change that improves performance",
+		Description: "Use it for changes that make the project faster or use less memory without changing its behavior. It bumps the patch version.",
+		Examples: []string{
+			"perf: compile the header regex only once",
+			"perf(index): cache the scopes of the git history",
+		},
+	}
+	refactorDoc = TypeDoc{
+		Summary:     "A code change that neither fixes a bug nor adds a feature",
+		Description: "Use it for restructuring code without changing its behavior.",
+		Examples: []string{
+			"refactor: move the commit parser into its own package",
+			"refactor(rpc): split the header parsing from the decoding",
+		},
+	}
+	styleDoc = TypeDoc{
+		Summary:     "Changes that do not affect the meaning of the code (white-space, formatting, missing semi-colons, etc)",
+		Description: "Use it for formatting and linting fixes. Changes to the look of a user interface are `feat` or `fix`.",
+		Examples: []string{
+			"style: run gofmt",
+			"style(lsp): fix the indentation of the struct tags",
+		},
+	}
+	testDoc = TypeDoc{
+		Summary:     "Adding missing tests or correcting existing tests",
+		Description: "Use it for changes that only touch tests and test data.",
+		Examples: []string{
+			"test: cover the scissors line in the parser",
+			"test(release): check the pre-release numbering",
+		},
+	}
 )
 
-// maps message types to hover content
-var HoverContents = map[string]Content{
-	"build":    buildContent,
-	"ci":       ciContent,
-	"docs":     docsContent,
-	"feat":     featContent,
-	"fix":      fixContent,
-	"perf":     perfContent,
-	"refactor": refactorContent,
-	"style":    styleContent,
-	"test":     testContent,
+// BreakingDoc documents the `!` that marks a breaking change
+var BreakingDoc = TypeDoc{
+	Summary:     "A breaking change, the commit changes the public API in an incompatible way",
+	Description: "A `!` after the type or scope, or a `BREAKING CHANGE:` footer, marks a breaking change. It bumps the major version.",
+	Examples: []string{
+		"feat(api)!: remove the v1 endpoints",
+		"refactor!: rename the config file\n\nBREAKING CHANGE: .cc-lsp.json replaces .cclsprc",
+	},
+}
+
+// maps message types to their documentation
+var TypeDocs = map[string]TypeDoc{
+	"build":    buildDoc,
+	"ci":       ciDoc,
+	"docs":     docsDoc,
+	"feat":     featDoc,
+	"fix":      fixDoc,
+	"perf":     perfDoc,
+	"refactor": refactorDoc,
+	"style":    styleDoc,
+	"test":     testDoc,
 }
 
 var Prefixes = []string{
@@ -40,19 +121,49 @@ var Prefixes = []string{
 	"test",
 }
 
+// Trailer is a footer token that is offered in the footer of the message
+type Trailer struct {
+	Key           string
+	Documentation string
+}
+
+var Trailers = []Trailer{
+	{Key: "BREAKING CHANGE", Documentation: "Describes a breaking change of the public API, it bumps the major version"},
+	{Key: "Refs", Documentation: "References issues or other commits related to this commit (e.g. Refs: #123)"},
+	{Key: "Signed-off-by", Documentation: "Certifies that the author wrote the change or has the right to submit it"},
+}
+
+// Markdown renders the documentation of the type with the given name
+func (d TypeDoc) Markdown(name string) string {
+	var b strings.Builder
+	b.WriteString("**" + name + "**: " + d.Summary + "\n\n" + d.Description + "\n\nExamples:\n\n```gitcommit\n")
+	b.WriteString(strings.Join(d.Examples, "\n\n"))
+	b.WriteString("\n```")
+	return b.String()
+}
+
+// PlainText renders the documentation of the type for clients without markdown
+func (d TypeDoc) PlainText(name string) string {
+	var b strings.Builder
+	b.WriteString(name + ": " + d.Summary + "\n\n" + d.Description + "\n\nExamples:\n")
+	for _, example := range d.Examples {
+		b.WriteString("\n    " + strings.ReplaceAll(example, "\n", "\n    "))
+	}
+	return b.String()
+}
+
 func GetCompletions() []CompletionItem {
 	completions := []CompletionItem{}
 	const keyword = 14
 	for _, item := range Prefixes {
-		documentation, ok := HoverContents[item]
+		documentation, ok := TypeDocs[item]
 		if !ok {
 			panic("There is no documentation for the given keyword!")
 		}
 		completion := CompletionItem{
-			Label:         item,
-			Kind:          keyword,
-			Detail:        string(documentation),
-			Documentation: string(documentation),
+			Label:  item,
+			Kind:   keyword,
+			Detail: documentation.Summary,
 		}
 		completions = append(completions, completion)
 	}
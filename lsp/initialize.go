@@ -1,15 +1,79 @@
 package lsp
 
+import "encoding/json"
+
 type InitializeRequest struct {
 	Request
 	Params InitializeRequestParams `json:"params"`
 }
 
 type InitializeRequestParams struct {
-	ClientInfo *ClientInfo `json:"clientInfo"`
+	ClientInfo   *ClientInfo        `json:"clientInfo"`
+	Capabilities ClientCapabilities `json:"capabilities"`
+	// InitializationOptions are the settings of the server in the editor
+	InitializationOptions json.RawMessage `json:"initializationOptions"`
 	// ... there's tons more that goes here
 }
 
+// ClientCapabilities holds the parts of the client capabilities the server uses
+type ClientCapabilities struct {
+	General      GeneralClientCapabilities      `json:"general"`
+	TextDocument TextDocumentClientCapabilities `json:"textDocument"`
+	Workspace    WorkspaceClientCapabilities    `json:"workspace"`
+	Window       WindowClientCapabilities       `json:"window"`
+}
+
+type GeneralClientCapabilities struct {
+	// PositionEncodings lists the encodings of the characters of positions
+	// the client supports, UTF-16 if it is empty
+	PositionEncodings []string `json:"positionEncodings"`
+}
+
+const (
+	UTF8Encoding  = "utf-8"
+	UTF16Encoding = "utf-16"
+)
+
+// WorkspaceClientCapabilities tell which requests the server may send
+type WorkspaceClientCapabilities struct {
+	ApplyEdit              bool                `json:"applyEdit"`
+	Configuration          bool                `json:"configuration"`
+	DidChangeConfiguration DynamicRegistration `json:"didChangeConfiguration"`
+	DidChangeWatchedFiles  DynamicRegistration `json:"didChangeWatchedFiles"`
+}
+
+// DynamicRegistration tells whether the capability can be registered with
+// client/registerCapability
+type DynamicRegistration struct {
+	DynamicRegistration bool `json:"dynamicRegistration"`
+}
+
+type WindowClientCapabilities struct {
+	WorkDoneProgress bool `json:"workDoneProgress"`
+	ShowMessage      struct {
+		// MessageActionItem is set if the client supports actions
+		MessageActionItem *struct{} `json:"messageActionItem"`
+	} `json:"showMessage"`
+}
+
+type TextDocumentClientCapabilities struct {
+	Hover      HoverClientCapabilities      `json:"hover"`
+	Completion CompletionClientCapabilities `json:"completion"`
+}
+
+type CompletionClientCapabilities struct {
+	CompletionItem struct {
+		SnippetSupport bool `json:"snippetSupport"`
+		// DocumentationFormat lists the supported markup kinds, the preferred first
+		DocumentationFormat []string `json:"documentationFormat"`
+	} `json:"completionItem"`
+}
+
+type HoverClientCapabilities struct {
+	// ContentFormat lists the supported markup kinds, the preferred first
+	ContentFormat []string `json:"contentFormat"`
+}
+
 type ClientInfo struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
@@ -26,12 +90,41 @@ type InitializeResult struct {
 }
 
 type ServerCapabilities struct {
-	TextDocumentSync int `json:"textDocumentSync"`
+	// PositionEncoding is the encoding the characters of positions count in
+	PositionEncoding string                  `json:"positionEncoding,omitempty"`
+	TextDocumentSync TextDocumentSyncOptions `json:"textDocumentSync"`
+
+	HoverProvider      bool              `json:"hoverProvider"`
+	DefinitionProvider bool              `json:"definitionProvider"`
+	CodeActionProvider bool              `json:"codeActionProvider"`
+	CompletionProvider CompletionOptions `json:"completionProvider"`
+
+	SemanticTokensProvider SemanticTokensOptions `json:"semanticTokensProvider"`
+	DocumentSymbolProvider bool                  `json:"documentSymbolProvider"`
+	FoldingRangeProvider   bool                  `json:"foldingRangeProvider"`
+	// DocumentFormattingProvider formats the message, the diff stays untouched
+	DocumentFormattingProvider       bool                            `json:"documentFormattingProvider"`
+	DocumentOnTypeFormattingProvider DocumentOnTypeFormattingOptions `json:"documentOnTypeFormattingProvider"`
+	InlayHintProvider                bool                            `json:"inlayHintProvider"`
+	CodeLensProvider                 CodeLensOptions                 `json:"codeLensProvider"`
+	ExecuteCommandProvider           ExecuteCommandOptions           `json:"executeCommandProvider"`
+}
+
+// TextDocumentSyncOptions asks for the full text on every change, the draft
+// of the message is kept when the document is saved or closed
+type TextDocumentSyncOptions struct {
+	OpenClose bool        `json:"openClose"`
+	Change    int         `json:"change"`
+	Save      SaveOptions `json:"save"`
+}
+
+type SaveOptions struct {
+	IncludeText bool `json:"includeText"`
+}
 
-	HoverProvider      bool           `json:"hoverProvider"`
-	DefinitionProvider bool           `json:"definitionProvider"`
-	CodeActionProvider bool           `json:"codeActionProvider"`
-	CompletionProvider map[string]any `json:"completionProvider"`
+type CompletionOptions struct {
+	TriggerCharacters []string `json:"triggerCharacters,omitempty"`
+	ResolveProvider   bool     `json:"resolveProvider"`
 }
 
 type ServerInfo struct {
@@ -47,9 +140,36 @@ func NewInitializeResponse(id int) InitializeResponse {
 		},
 		Result: InitializeResult{
 			Capabilities: ServerCapabilities{
-				TextDocumentSync:   1,
-				HoverProvider:      true,
-				CompletionProvider: map[string]any{},
+				TextDocumentSync: TextDocumentSyncOptions{OpenClose: true, Change: 1},
+				HoverProvider:    true,
+				// the quick fixes for the rule diagnostics
+				CodeActionProvider: true,
+				CompletionProvider: CompletionOptions{
+					// a new line triggers the completion at the line start
+					TriggerCharacters: []string{"(", ":", "\n"},
+					ResolveProvider:   true,
+				},
+				SemanticTokensProvider: SemanticTokensOptions{
+					Legend: SemanticTokensLegend{
+						TokenTypes:     SemanticTokenTypes,
+						TokenModifiers: SemanticTokenModifiers,
+					},
+					Range: true,
+					Full:  true,
+				},
+				DocumentSymbolProvider:     true,
+				FoldingRangeProvider:       true,
+				DocumentFormattingProvider: true,
+				DocumentOnTypeFormattingProvider: DocumentOnTypeFormattingOptions{
+					// a space wraps the body line that got too long
+					FirstTriggerCharacter: ":",
+					MoreTriggerCharacter:  []string{"(", "\n", " "},
+				},
+				InlayHintProvider: true,
+				CodeLensProvider:  CodeLensOptions{},
+				ExecuteCommandProvider: ExecuteCommandOptions{
+					Commands: Commands,
+				},
 			},
 			ServerInfo: ServerInfo{
 				Name:    "cc-lsp",
@@ -1,5 +1,7 @@
 package lsp
 
+import "encoding/json"
+
 type Request struct {
 	RPC    string `json:"jsonrpc"`
 	ID     int    `json:"id"`
@@ -21,3 +23,20 @@ type Notification struct {
 	RPC    string `json:"jsonrpc"`
 	Method string `json:"method"`
 }
+
+// ResponseMessage is a response of the client to a request of the server
+type ResponseMessage struct {
+	RPC    string          `json:"jsonrpc"`
+	ID     *int            `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *ResponseError  `json:"error"`
+}
+
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	return e.Message
+}
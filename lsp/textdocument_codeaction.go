@@ -0,0 +1,32 @@
+package lsp
+
+type CodeActionRequest struct {
+	Request
+	Params TextDocumentCodeActionParams `json:"params"`
+}
+
+type TextDocumentCodeActionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+	Context      CodeActionContext      `json:"context"`
+}
+
+type CodeActionContext struct {
+	// Diagnostics are the diagnostics of the client at the range
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+type TextDocumentCodeActionResponse struct {
+	Response
+	Result []CodeAction `json:"result"`
+}
+
+const QuickFix = "quickfix"
+
+type CodeAction struct {
+	Title       string         `json:"title"`
+	Kind        string         `json:"kind,omitempty"`
+	Diagnostics []Diagnostic   `json:"diagnostics,omitempty"`
+	IsPreferred bool           `json:"isPreferred,omitempty"`
+	Edit        *WorkspaceEdit `json:"edit,omitempty"`
+}
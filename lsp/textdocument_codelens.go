@@ -0,0 +1,32 @@
+package lsp
+
+type CodeLensRequest struct {
+	Request
+	Params CodeLensParams `json:"params"`
+}
+
+type CodeLensParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type CodeLensResponse struct {
+	Response
+	Result []CodeLens `json:"result"`
+}
+
+type CodeLens struct {
+	Range Range `json:"range"`
+	// Command is shown as the title of the lens, a lens with an empty command
+	// name cannot be clicked
+	Command *Command `json:"command,omitempty"`
+}
+
+type CodeLensOptions struct {
+	ResolveProvider bool `json:"resolveProvider"`
+}
+
+type Command struct {
+	Title     string `json:"title"`
+	Command   string `json:"command"`
+	Arguments []any  `json:"arguments,omitempty"`
+}
@@ -1,5 +1,7 @@
 package lsp
 
+import "encoding/json"
+
 type CompletionRequest struct {
 	Request
 	Params CompletionParams `json:"params"`
@@ -15,8 +17,38 @@ type CompletionResponse struct {
 }
 
 type CompletionItem struct {
-	Label         string `json:"label"`
-	Detail        string `json:"detail"`
-	Kind          int    `json:"kind"`
-	Documentation string `json:"documentation"`
+	Label  string `json:"label"`
+	Detail string `json:"detail"`
+	Kind   int    `json:"kind"`
+	// Documentation is added by completionItem/resolve to keep the list small
+	Documentation *MarkupContent `json:"documentation,omitempty"`
+	// SortText orders the items in the client, the label is used if it is empty
+	SortText string `json:"sortText,omitempty"`
+	// FilterText is matched against the typed text, the label is used if it is empty
+	FilterText string `json:"filterText,omitempty"`
+	// InsertText is inserted instead of the label, TextEdit wins over it
+	InsertText       string    `json:"insertText,omitempty"`
+	InsertTextFormat int       `json:"insertTextFormat,omitempty"`
+	TextEdit         *TextEdit `json:"textEdit,omitempty"`
+	// Preselect selects the item when the list is shown
+	Preselect bool `json:"preselect,omitempty"`
+	// Data is sent back by the client to resolve the item
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+type CompletionResolveRequest struct {
+	Request
+	Params CompletionItem `json:"params"`
 }
+
+type CompletionResolveResponse struct {
+	Response
+	Result CompletionItem `json:"result"`
+}
+
+// the formats of CompletionItem.InsertText
+const (
+	PlainTextFormat = 1
+	// SnippetFormat supports tab stops like ${1:scope} and choices like ${1|a,b|}
+	SnippetFormat = 2
+)
@@ -15,4 +15,13 @@ type Diagnostic struct {
 	Severity int    `json:"severity"`
 	Source   string `json:"source"`
 	Message  string `json:"message"`
+	// Code identifies the kind of problem, code actions use it to find fixes
+	Code string `json:"code,omitempty"`
+	// RelatedInformation points to the places that caused the diagnostic
+	RelatedInformation []DiagnosticRelatedInformation `json:"relatedInformation,omitempty"`
+}
+
+type DiagnosticRelatedInformation struct {
+	Location Location `json:"location"`
+	Message  string   `json:"message"`
 }
@@ -0,0 +1,39 @@
+package lsp
+
+type DocumentFormattingRequest struct {
+	Request
+	Params DocumentFormattingParams `json:"params"`
+}
+
+type DocumentFormattingParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Options      FormattingOptions      `json:"options"`
+}
+
+// FormattingOptions are ignored, a commit message is never indented
+type FormattingOptions struct {
+	TabSize      int  `json:"tabSize"`
+	InsertSpaces bool `json:"insertSpaces"`
+}
+
+type DocumentFormattingResponse struct {
+	Response
+	Result []TextEdit `json:"result"`
+}
+
+type DocumentOnTypeFormattingRequest struct {
+	Request
+	Params DocumentOnTypeFormattingParams `json:"params"`
+}
+
+type DocumentOnTypeFormattingParams struct {
+	TextDocumentPositionParams
+	// Ch is the character that was typed
+	Ch      string            `json:"ch"`
+	Options FormattingOptions `json:"options"`
+}
+
+type DocumentOnTypeFormattingOptions struct {
+	FirstTriggerCharacter string   `json:"firstTriggerCharacter"`
+	MoreTriggerCharacter  []string `json:"moreTriggerCharacter,omitempty"`
+}
@@ -11,9 +11,22 @@ type HoverParams struct {
 
 type HoverResponse struct {
 	Response
-	Result HoverResult `json:"result"`
+	// Result is null if there is nothing to show
+	Result *HoverResult `json:"result"`
 }
 
 type HoverResult struct {
-	Contents string `json:"contents"`
+	Contents MarkupContent `json:"contents"`
+	Range    *Range        `json:"range,omitempty"`
+}
+
+const (
+	PlainText = "plaintext"
+	Markdown  = "markdown"
+)
+
+type MarkupContent struct {
+	// Kind is either PlainText or Markdown
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
 }
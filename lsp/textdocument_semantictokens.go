@@ -0,0 +1,74 @@
+package lsp
+
+type SemanticTokensRequest struct {
+	Request
+	Params SemanticTokensParams `json:"params"`
+}
+
+type SemanticTokensParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type SemanticTokensRangeRequest struct {
+	Request
+	Params SemanticTokensRangeParams `json:"params"`
+}
+
+type SemanticTokensRangeParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+}
+
+type SemanticTokensResponse struct {
+	Response
+	Result SemanticTokens `json:"result"`
+}
+
+// SemanticTokens holds five integers per token: the line relative to the
+// previous token, the start relative to the previous token on the same line,
+// the length, the index of the token type and the bit set of the modifiers
+type SemanticTokens struct {
+	Data []int `json:"data"`
+}
+
+type SemanticTokensOptions struct {
+	Legend SemanticTokensLegend `json:"legend"`
+	Range  bool                 `json:"range"`
+	Full   bool                 `json:"full"`
+}
+
+type SemanticTokensLegend struct {
+	TokenTypes     []string `json:"tokenTypes"`
+	TokenModifiers []string `json:"tokenModifiers"`
+}
+
+// The token types in the order of the legend
+const (
+	CommitTypeToken = iota
+	ScopeToken
+	BreakingMarkerToken
+	DescriptionToken
+	FooterTokenToken
+	FooterValueToken
+	IssueReferenceToken
+	CommentToken
+)
+
+// The token modifiers as bits of the modifier set
+const (
+	// UnknownModifier marks a commit type that is not a conventional type
+	UnknownModifier = 1 << iota
+)
+
+var SemanticTokenTypes = []string{
+	"commitType",
+	"scope",
+	"breakingMarker",
+	"description",
+	"footerToken",
+	"footerValue",
+	"issueReference",
+	"comment",
+}
+
+var SemanticTokenModifiers = []string{"unknown"}
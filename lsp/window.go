@@ -0,0 +1,68 @@
+package lsp
+
+// the types of the messages shown to the user
+const (
+	ErrorMessage   = 1
+	WarningMessage = 2
+	InfoMessage    = 3
+	LogMessage     = 4
+)
+
+type ShowMessageNotification struct {
+	Notification
+	Params ShowMessageParams `json:"params"`
+}
+
+type ShowMessageParams struct {
+	Type    int    `json:"type"`
+	Message string `json:"message"`
+}
+
+type ShowDocumentRequest struct {
+	Request
+	Params ShowDocumentParams `json:"params"`
+}
+
+type ShowDocumentParams struct {
+	URI       string `json:"uri"`
+	TakeFocus bool   `json:"takeFocus,omitempty"`
+}
+
+type ShowDocumentResult struct {
+	Success bool `json:"success"`
+}
+
+type ShowMessageRequestParams struct {
+	Type    int                 `json:"type"`
+	Message string              `json:"message"`
+	Actions []MessageActionItem `json:"actions,omitempty"`
+}
+
+type MessageActionItem struct {
+	Title string `json:"title"`
+}
+
+type WorkDoneProgressCreateParams struct {
+	Token string `json:"token"`
+}
+
+type ProgressNotification struct {
+	Notification
+	Params ProgressParams `json:"params"`
+}
+
+type ProgressParams struct {
+	Token string `json:"token"`
+	Value any    `json:"value"`
+}
+
+type WorkDoneProgressBegin struct {
+	Kind    string `json:"kind"`
+	Title   string `json:"title"`
+	Message string `json:"message,omitempty"`
+}
+
+type WorkDoneProgressEnd struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message,omitempty"`
+}
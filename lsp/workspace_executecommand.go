@@ -0,0 +1,57 @@
+package lsp
+
+import "encoding/json"
+
+// the commands of the server, all but reloadConfig take the URI of the
+// commit message as their argument
+const (
+	GenerateFromDiffCommand = "cc-lsp.generateFromDiff"
+	InsertTemplateCommand   = "cc-lsp.insertTemplate"
+	PreviewChangelogCommand = "cc-lsp.previewChangelog"
+	ReloadConfigCommand     = "cc-lsp.reloadConfig"
+	RestoreDraftCommand     = "cc-lsp.restoreDraft"
+)
+
+// Commands lists the commands of the server
+var Commands = []string{
+	GenerateFromDiffCommand,
+	InsertTemplateCommand,
+	PreviewChangelogCommand,
+	ReloadConfigCommand,
+	RestoreDraftCommand,
+}
+
+type ExecuteCommandRequest struct {
+	Request
+	Params ExecuteCommandParams `json:"params"`
+}
+
+type ExecuteCommandParams struct {
+	Command   string            `json:"command"`
+	Arguments []json.RawMessage `json:"arguments"`
+}
+
+type ExecuteCommandResponse struct {
+	Response
+	Result any `json:"result"`
+}
+
+type ExecuteCommandOptions struct {
+	Commands []string `json:"commands"`
+}
+
+type ApplyWorkspaceEditRequest struct {
+	Request
+	Params ApplyWorkspaceEditParams `json:"params"`
+}
+
+type ApplyWorkspaceEditParams struct {
+	// Label is shown in the undo stack of the client
+	Label string        `json:"label,omitempty"`
+	Edit  WorkspaceEdit `json:"edit"`
+}
+
+type ApplyWorkspaceEditResult struct {
+	Applied       bool   `json:"applied"`
+	FailureReason string `json:"failureReason,omitempty"`
+}
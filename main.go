@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"cc-lsp/analysis"
+	"cc-lsp/config"
 	"cc-lsp/lsp"
 	"cc-lsp/rpc"
 	"encoding/json"
@@ -12,6 +13,12 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if _, ok := commands[os.Args[1]]; ok {
+			os.Exit(runCommand(os.Args[1], os.Args[2:]))
+		}
+	}
+
 	home := os.Getenv("HOME")
 	logger := getLogger(home + "/git/cc-lsp/log.txt")
 	logger.Println("Hey, I started!")
@@ -20,21 +27,23 @@ func main() {
 	scanner.Split(rpc.Split)
 
 	state := analysis.NewState()
-	writer := os.Stdout
+	client := newConnection(os.Stdout)
 
-	for scanner.Scan() {
-		msg := scanner.Bytes()
-		method, contents, err := rpc.DecodeMessage(msg)
-		if err != nil {
-			logger.Printf("Got an error: %s", err)
+	go client.read(logger, scanner)
+	for {
+		msg, ok := client.next()
+		if !ok {
+			return
+		}
+		if msg.callback != nil {
+			msg.callback()
 			continue
 		}
-
-		handleMessage(logger, writer, state, method, contents)
+		handleMessage(logger, client, &state, msg.method, msg.contents)
 	}
 }
 
-func handleMessage(logger *log.Logger, writer io.Writer, state analysis.State, method string, contents []byte) {
+func handleMessage(logger *log.Logger, client *connection, state *analysis.State, method string, contents []byte) {
 	logger.Printf("Received msg with method: %s", method)
 
 	switch method {
@@ -44,13 +53,18 @@ func handleMessage(logger *log.Logger, writer io.Writer, state analysis.State, m
 			logger.Printf("Hey, we couldn't parse this: %s", err)
 		}
 
-		logger.Printf("Connected to: %s %s",
-			request.Params.ClientInfo.Name,
-			request.Params.ClientInfo.Version)
+		if info := request.Params.ClientInfo; info != nil {
+			logger.Printf("Connected to: %s %s", info.Name, info.Version)
+		}
+		state.Capabilities = request.Params.Capabilities
+		if err := state.InitializationOptions(request.Params.InitializationOptions); err != nil {
+			showMessage(client, lsp.ErrorMessage, err.Error())
+		}
 
 		// hey... let's reply!
 		msg := lsp.NewInitializeResponse(request.ID)
-		writeResponse(writer, msg)
+		msg.Result.Capabilities.PositionEncoding = state.PositionEncoding()
+		writeResponse(client, msg)
 
 		logger.Print("Sent the reply")
 	case "textDocument/didOpen":
@@ -61,17 +75,8 @@ func handleMessage(logger *log.Logger, writer io.Writer, state analysis.State, m
 		}
 
 		logger.Printf("Opened: %s", request.Params.TextDocument.URI)
-		diagnostics := state.OpenDocument(request.Params.TextDocument.URI, request.Params.TextDocument.Text)
-		writeResponse(writer, lsp.PublishDiagnosticsNotification{
-			Notification: lsp.Notification{
-				RPC:    "2.0",
-				Method: "textDocument/publishDiagnostics",
-			},
-			Params: lsp.PublishDiagnosticsParams{
-				URI:         request.Params.TextDocument.URI,
-				Diagnostics: diagnostics,
-			},
-		})
+		diagnostics := state.OpenTextDocument(request.Params.TextDocument)
+		publishDiagnostics(client, request.Params.TextDocument.URI, diagnostics)
 	case "textDocument/didChange":
 		var request lsp.TextDocumentDidChangeNotification
 		if err := json.Unmarshal(contents, &request); err != nil {
@@ -82,16 +87,28 @@ func handleMessage(logger *log.Logger, writer io.Writer, state analysis.State, m
 		logger.Printf("Changed: %s", request.Params.TextDocument.URI)
 		for _, change := range request.Params.ContentChanges {
 			diagnostics := state.UpdateDocument(request.Params.TextDocument.URI, change.Text)
-			writeResponse(writer, lsp.PublishDiagnosticsNotification{
-				Notification: lsp.Notification{
-					RPC:    "2.0",
-					Method: "textDocument/publishDiagnostics",
-				},
-				Params: lsp.PublishDiagnosticsParams{
-					URI:         request.Params.TextDocument.URI,
-					Diagnostics: diagnostics,
-				},
-			})
+			publishDiagnostics(client, request.Params.TextDocument.URI, diagnostics)
+		}
+	case "textDocument/didSave":
+		var request lsp.DidSaveTextDocumentNotification
+		if err := json.Unmarshal(contents, &request); err != nil {
+			logger.Printf("textDocument/didSave: %s", err)
+			return
+		}
+
+		if err := state.SaveDocument(request.Params.TextDocument.URI); err != nil {
+			logger.Printf("textDocument/didSave: %s", err)
+		}
+	case "textDocument/didClose":
+		var request lsp.DidCloseTextDocumentNotification
+		if err := json.Unmarshal(contents, &request); err != nil {
+			logger.Printf("textDocument/didClose: %s", err)
+			return
+		}
+
+		logger.Printf("Closed: %s", request.Params.TextDocument.URI)
+		if err := state.CloseDocument(request.Params.TextDocument.URI); err != nil {
+			logger.Printf("textDocument/didClose: %s", err)
 		}
 	case "textDocument/hover":
 		var request lsp.HoverRequest
@@ -104,7 +121,7 @@ func handleMessage(logger *log.Logger, writer io.Writer, state analysis.State, m
 		response := state.Hover(request.ID, request.Params.TextDocument.URI, request.Params.Position)
 
 		// Write it back
-		writeResponse(writer, response)
+		writeResponse(client, response)
 	case "textDocument/completion":
 		var request lsp.CompletionRequest
 		if err := json.Unmarshal(contents, &request); err != nil {
@@ -113,13 +130,324 @@ func handleMessage(logger *log.Logger, writer io.Writer, state analysis.State, m
 		}
 
 		// Create a response
-		response := state.TextDocumentCompletion(request.ID, request.Params.TextDocument.URI)
+		response := state.TextDocumentCompletion(request.ID, request.Params.TextDocument.URI, request.Params.Position)
 
 		// Write it back
-		writeResponse(writer, response)
+		writeResponse(client, response)
+	case "completionItem/resolve":
+		var request lsp.CompletionResolveRequest
+		if err := json.Unmarshal(contents, &request); err != nil {
+			logger.Printf("completionItem/resolve: %s", err)
+			return
+		}
+
+		response := state.ResolveCompletion(request.ID, request.Params)
+		writeResponse(client, response)
+	case "textDocument/codeAction":
+		var request lsp.CodeActionRequest
+		if err := json.Unmarshal(contents, &request); err != nil {
+			logger.Printf("textDocument/codeAction: %s", err)
+			return
+		}
+
+		response := state.TextDocumentCodeAction(request.ID, request.Params.TextDocument.URI, request.Params.Context)
+		writeResponse(client, response)
+	case "textDocument/semanticTokens/full":
+		var request lsp.SemanticTokensRequest
+		if err := json.Unmarshal(contents, &request); err != nil {
+			logger.Printf("textDocument/semanticTokens/full: %s", err)
+			return
+		}
+
+		response := state.SemanticTokens(request.ID, request.Params.TextDocument.URI)
+		writeResponse(client, response)
+	case "textDocument/semanticTokens/range":
+		var request lsp.SemanticTokensRangeRequest
+		if err := json.Unmarshal(contents, &request); err != nil {
+			logger.Printf("textDocument/semanticTokens/range: %s", err)
+			return
+		}
+
+		response := state.SemanticTokensRange(request.ID, request.Params.TextDocument.URI, request.Params.Range)
+		writeResponse(client, response)
+	case "textDocument/documentSymbol":
+		var request lsp.DocumentSymbolRequest
+		if err := json.Unmarshal(contents, &request); err != nil {
+			logger.Printf("textDocument/documentSymbol: %s", err)
+			return
+		}
+
+		response := state.DocumentSymbols(request.ID, request.Params.TextDocument.URI)
+		writeResponse(client, response)
+	case "textDocument/foldingRange":
+		var request lsp.FoldingRangeRequest
+		if err := json.Unmarshal(contents, &request); err != nil {
+			logger.Printf("textDocument/foldingRange: %s", err)
+			return
+		}
+
+		response := state.FoldingRanges(request.ID, request.Params.TextDocument.URI)
+		writeResponse(client, response)
+	case "textDocument/formatting":
+		var request lsp.DocumentFormattingRequest
+		if err := json.Unmarshal(contents, &request); err != nil {
+			logger.Printf("textDocument/formatting: %s", err)
+			return
+		}
+
+		response := state.Formatting(request.ID, request.Params.TextDocument.URI)
+		writeResponse(client, response)
+	case "textDocument/onTypeFormatting":
+		var request lsp.DocumentOnTypeFormattingRequest
+		if err := json.Unmarshal(contents, &request); err != nil {
+			logger.Printf("textDocument/onTypeFormatting: %s", err)
+			return
+		}
+
+		response := state.OnTypeFormatting(request.ID, request.Params.TextDocument.URI, request.Params.Position, request.Params.Ch)
+		writeResponse(client, response)
+	case "textDocument/inlayHint":
+		var request lsp.InlayHintRequest
+		if err := json.Unmarshal(contents, &request); err != nil {
+			logger.Printf("textDocument/inlayHint: %s", err)
+			return
+		}
+
+		response := state.InlayHint(request.ID, request.Params.TextDocument.URI, request.Params.Range)
+		writeResponse(client, response)
+	case "textDocument/codeLens":
+		var request lsp.CodeLensRequest
+		if err := json.Unmarshal(contents, &request); err != nil {
+			logger.Printf("textDocument/codeLens: %s", err)
+			return
+		}
+
+		response := state.CodeLens(request.ID, request.Params.TextDocument.URI)
+		writeResponse(client, response)
+	case "workspace/executeCommand":
+		var request lsp.ExecuteCommandRequest
+		if err := json.Unmarshal(contents, &request); err != nil {
+			logger.Printf("workspace/executeCommand: %s", err)
+			return
+		}
+
+		executeCommand(logger, client, state, request)
+	case "initialized":
+		workspace := state.Capabilities.Workspace
+		registrations := []lsp.Registration{}
+		if workspace.DidChangeConfiguration.DynamicRegistration {
+			registrations = append(registrations, lsp.Registration{ID: "cc-lsp-configuration", Method: "workspace/didChangeConfiguration"})
+		}
+		if workspace.DidChangeWatchedFiles.DynamicRegistration {
+			registrations = append(registrations, lsp.Registration{
+				ID:              "cc-lsp-config-files",
+				Method:          "workspace/didChangeWatchedFiles",
+				RegisterOptions: configWatchers(),
+			})
+		}
+		if len(registrations) > 0 {
+			// the messages that follow are handled while the client
+			// registers the capabilities
+			go func() {
+				if err := client.registerCapability(registrations...); err != nil {
+					logger.Print(err)
+				}
+			}()
+		}
+		if workspace.Configuration {
+			pullSettings(logger, client, state)
+		}
+	case "workspace/didChangeConfiguration":
+		var request lsp.DidChangeConfigurationNotification
+		if err := json.Unmarshal(contents, &request); err != nil {
+			logger.Printf("workspace/didChangeConfiguration: %s", err)
+			return
+		}
+
+		// clients that support workspace/configuration only tell that
+		// something changed
+		if state.Capabilities.Workspace.Configuration {
+			pullSettings(logger, client, state)
+			return
+		}
+		changeSettings(client, state, request.Params.Settings[config.Section])
+	case "workspace/didChangeWatchedFiles":
+		var request lsp.DidChangeWatchedFilesNotification
+		if err := json.Unmarshal(contents, &request); err != nil {
+			logger.Printf("workspace/didChangeWatchedFiles: %s", err)
+			return
+		}
+
+		// the documents are linted once for all the changes
+		uris := []string{}
+		for _, change := range request.Params.Changes {
+			logger.Printf("Config changed: %s", change.URI)
+			uris = append(uris, change.URI)
+		}
+		diagnostics, err := state.ConfigFilesChanged(uris)
+		if err != nil {
+			showMessage(client, lsp.ErrorMessage, err.Error())
+		}
+		for uri, diagnostics := range diagnostics {
+			publishDiagnostics(client, uri, diagnostics)
+		}
 	}
 }
 
+// configWatchers watch the config files in every repository of the workspace
+func configWatchers() lsp.DidChangeWatchedFilesRegistrationOptions {
+	options := lsp.DidChangeWatchedFilesRegistrationOptions{}
+	for _, name := range config.Files {
+		options.Watchers = append(options.Watchers, lsp.FileSystemWatcher{GlobPattern: "**/" + name})
+	}
+	return options
+}
+
+// pullSettings asks the client for the settings of the server, the answer
+// is awaited in its own goroutine and the settings are changed on the loop
+func pullSettings(logger *log.Logger, client *connection, state *analysis.State) {
+	go func() {
+		settings, err := client.configuration(lsp.ConfigurationItem{Section: config.Section})
+		if err != nil {
+			logger.Print(err)
+			return
+		}
+		if len(settings) > 0 {
+			client.post(func() { changeSettings(client, state, settings[0]) })
+		}
+	}()
+}
+
+// changeSettings lints every document with the new settings, invalid
+// settings are shown to the user
+func changeSettings(client *connection, state *analysis.State, settings json.RawMessage) {
+	diagnostics, err := state.ChangeSettings(settings)
+	if err != nil {
+		showMessage(client, lsp.ErrorMessage, err.Error())
+		return
+	}
+	for uri, diagnostics := range diagnostics {
+		publishDiagnostics(client, uri, diagnostics)
+	}
+}
+
+// executeCommand runs a command of the server and answers the request once
+// the client applied the edit of the command, failures are shown to the user
+func executeCommand(logger *log.Logger, client *connection, state *analysis.State, request lsp.ExecuteCommandRequest) {
+	var uri string
+	if arguments := request.Params.Arguments; len(arguments) > 0 {
+		if err := json.Unmarshal(arguments[0], &uri); err != nil {
+			logger.Printf("%s: %s", request.Params.Command, err)
+		}
+	}
+	title, long := longCommands[request.Params.Command]
+	if !long || !state.Capabilities.Window.WorkDoneProgress {
+		runServerCommand(logger, client, state, request, uri, progress{})
+		return
+	}
+
+	// the client has to create the token before the progress is reported
+	go func() {
+		task := client.createProgress(title)
+		client.post(func() { runServerCommand(logger, client, state, request, uri, task) })
+	}()
+}
+
+// runServerCommand runs the command on the handler loop, the edit is confirmed
+// and applied in its own goroutine
+func runServerCommand(logger *log.Logger, client *connection, state *analysis.State, request lsp.ExecuteCommandRequest, uri string, task progress) {
+	respond := func(result any) {
+		writeResponse(client, lsp.ExecuteCommandResponse{
+			Response: lsp.Response{
+				RPC: "2.0",
+				ID:  &request.ID,
+			},
+			Result: result,
+		})
+	}
+
+	command := request.Params.Command
+	result, err := state.ExecuteCommand(command, uri)
+	task.end("")
+	if err != nil {
+		showMessage(client, lsp.ErrorMessage, command+": "+err.Error())
+		respond(nil)
+		return
+	}
+
+	for uri, diagnostics := range result.Diagnostics {
+		publishDiagnostics(client, uri, diagnostics)
+	}
+	if result.Message != "" {
+		showMessage(client, lsp.InfoMessage, result.Message)
+	}
+	if result.Show != "" {
+		go func() {
+			if shown, err := client.showDocument(result.Show); err != nil {
+				logger.Print(err)
+			} else if !shown.Success {
+				logger.Printf("window/showDocument could not show %s", result.Show)
+			}
+		}()
+	}
+	if result.Edit == nil {
+		respond(nil)
+		return
+	}
+
+	confirm := result.Confirm != "" && state.Capabilities.Window.ShowMessage.MessageActionItem != nil
+	go func() {
+		if confirm {
+			action, err := client.showMessageRequest(lsp.WarningMessage, result.Confirm, "Replace", "Cancel")
+			if err != nil {
+				logger.Print(err)
+			}
+			if action == nil || action.Title != "Replace" {
+				respond(nil)
+				return
+			}
+		}
+
+		applied, err := client.applyEdit(result.Label, *result.Edit)
+		if err != nil {
+			logger.Print(err)
+		} else if !applied.Applied {
+			logger.Printf("workspace/applyEdit was not applied: %s", applied.FailureReason)
+		}
+		respond(applied)
+	}()
+}
+
+// longCommands read the history of the repository, their progress is shown
+// with the title if the client supports it
+var longCommands = map[string]string{
+	lsp.PreviewChangelogCommand: "Previewing the changelog",
+	lsp.ReloadConfigCommand:     "Reloading the configuration",
+}
+
+func showMessage(writer io.Writer, typ int, message string) {
+	writeResponse(writer, lsp.ShowMessageNotification{
+		Notification: lsp.Notification{
+			RPC:    "2.0",
+			Method: "window/showMessage",
+		},
+		Params: lsp.ShowMessageParams{Type: typ, Message: message},
+	})
+}
+
+func publishDiagnostics(writer io.Writer, uri string, diagnostics []lsp.Diagnostic) {
+	writeResponse(writer, lsp.PublishDiagnosticsNotification{
+		Notification: lsp.Notification{
+			RPC:    "2.0",
+			Method: "textDocument/publishDiagnostics",
+		},
+		Params: lsp.PublishDiagnosticsParams{
+			URI:         uri,
+			Diagnostics: diagnostics,
+		},
+	})
+}
+
 func writeResponse(writer io.Writer, msg any) {
 	reply := rpc.EncodeMessage(msg)
 	writer.Write([]byte(reply))
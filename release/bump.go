@@ -0,0 +1,67 @@
+package release
+
+import "cc-lsp/conventional"
+
+// Bump is the change a set of commits makes to the version
+type Bump int
+
+const (
+	NoBump Bump = iota
+	PatchBump
+	MinorBump
+	MajorBump
+)
+
+func (b Bump) String() string {
+	switch b {
+	case PatchBump:
+		return "patch"
+	case MinorBump:
+		return "minor"
+	case MajorBump:
+		return "major"
+	}
+	return "none"
+}
+
+// BumpFor classifies a single commit: breaking changes bump the major
+// version, features the minor and fixes and performance improvements the
+// patch version
+func BumpFor(commit conventional.Commit) Bump {
+	if !commit.Conventional {
+		return NoBump
+	}
+	if commit.Breaking {
+		return MajorBump
+	}
+	switch commit.Type {
+	case "feat":
+		return MinorBump
+	case "fix", "perf":
+		return PatchBump
+	}
+	return NoBump
+}
+
+// BumpForMessages returns the highest bump of all commit messages
+func BumpForMessages(messages []string) Bump {
+	bump := NoBump
+	for _, message := range messages {
+		bump = max(bump, BumpFor(conventional.Parse(message)))
+	}
+	return bump
+}
+
+// Apply returns the next stable version after v
+func (b Bump) Apply(v Version) Version {
+	v = v.Stable()
+	switch b {
+	case MajorBump:
+		return Version{Major: v.Major + 1}
+	case MinorBump:
+		return Version{Major: v.Major, Minor: v.Minor + 1}
+	case PatchBump:
+		return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+	return v
+}
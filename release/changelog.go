@@ -0,0 +1,145 @@
+package release
+
+import (
+	"cc-lsp/conventional"
+	"cc-lsp/git"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	ChangelogFile  = "CHANGELOG.md"
+	changelogTitle = "# Changelog"
+)
+
+// section is a part of the release notes that collects one kind of commits
+type section struct {
+	title   string
+	entries []string
+}
+
+// the titles of the sections of the release notes
+const breakingTitle = "⚠ BREAKING CHANGES"
+
+var sectionTitles = map[string]string{
+	"feat": "Features",
+	"fix":  "Bug Fixes",
+	"perf": "Performance Improvements",
+}
+
+// RenderNotes renders the release notes of a version as markdown, only
+// breaking changes, features, fixes and performance improvements are listed
+func RenderNotes(version Version, date time.Time, commits []git.Commit) string {
+	breaking := section{title: breakingTitle}
+	sections := map[string]*section{}
+	for typ, title := range sectionTitles {
+		sections[typ] = &section{title: title}
+	}
+
+	for _, c := range commits {
+		commit := conventional.Parse(c.Message)
+		if !commit.Conventional {
+			continue
+		}
+		if s, ok := sections[commit.Type]; ok {
+			s.entries = append(s.entries, entry(commit.Scope, commit.Description, c.Hash))
+		}
+		if !commit.Breaking {
+			continue
+		}
+		breaking.entries = append(breaking.entries, entry(commit.Scope, breakingDescription(commit), c.Hash))
+	}
+
+	var notes strings.Builder
+	fmt.Fprintf(&notes, "## %s (%s)\n", version, date.Format(time.DateOnly))
+	for _, s := range []*section{&breaking, sections["feat"], sections["fix"], sections["perf"]} {
+		if len(s.entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(&notes, "\n### %s\n\n", s.title)
+		for _, e := range s.entries {
+			notes.WriteString(e + "\n")
+		}
+	}
+	return notes.String()
+}
+
+// NotesEntry returns the section and the entry the message gets in the
+// release notes, e.g. "Features" and "* **api:** add pagination". ok is false
+// if the commit is not listed.
+func NotesEntry(message string) (title, line string, ok bool) {
+	commit := conventional.Parse(message)
+	if !commit.Conventional {
+		return "", "", false
+	}
+	if title, ok := sectionTitles[commit.Type]; ok {
+		return title, entry(commit.Scope, commit.Description, ""), true
+	}
+	if commit.Breaking {
+		return breakingTitle, entry(commit.Scope, breakingDescription(commit), ""), true
+	}
+	return "", "", false
+}
+
+// breakingDescription prefers the BREAKING CHANGE footer to the description
+func breakingDescription(commit conventional.Commit) string {
+	description := commit.Description
+	for _, footer := range commit.Footers {
+		if footer.IsBreakingChange() {
+			description = footer.Value
+		}
+	}
+	return description
+}
+
+func entry(scope, description, hash string) string {
+	if len(hash) > 7 {
+		hash = hash[:7]
+	}
+	line := "* "
+	if scope != "" {
+		line += "**" + scope + ":** "
+	}
+	line += strings.ReplaceAll(description, "\n", "\n  ")
+	if hash != "" {
+		line += " (" + hash + ")"
+	}
+	return line
+}
+
+// PrependNotes adds the release notes on top of an existing changelog,
+// right below its title
+func PrependNotes(changelog, notes string) string {
+	rest, found := strings.CutPrefix(changelog, changelogTitle+"\n")
+	if !found {
+		rest = changelog
+	}
+	rest = strings.TrimLeft(rest, "\n")
+	if rest == "" {
+		return changelogTitle + "\n\n" + notes
+	}
+	return changelogTitle + "\n\n" + notes + "\n" + rest
+}
+
+// ChangelogPath returns the changelog of the release relative to the
+// repository root, packages keep their changelog in their own directory
+func (o Options) ChangelogPath() string {
+	if o.Package == nil || o.Package.Path == "" {
+		return ChangelogFile
+	}
+	return path.Join(filepath.ToSlash(o.Package.Path), ChangelogFile)
+}
+
+// UpdateChangelog prepends the release notes to the changelog at path, the
+// file is created if it does not exist
+func UpdateChangelog(path, notes string) error {
+	changelog, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.WriteFile(path, []byte(PrependNotes(string(changelog), notes)), 0666)
+}
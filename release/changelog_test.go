@@ -0,0 +1,75 @@
+package release
+
+import (
+	"cc-lsp/git"
+	"testing"
+	"time"
+)
+
+func TestRenderNotes(t *testing.T) {
+	commits := []git.Commit{
+		{Hash: "aaaaaaaaaa", Message: "docs: update the readme"},
+		{Hash: "bbbbbbbbbb", Message: "fix(parser): handle empty lines"},
+		{Hash: "cccccccccc", Message: "feat(api)!: add pagination\n\nBREAKING CHANGE: list endpoints return pages"},
+		{Hash: "dddddddddd", Message: "not conventional"},
+	}
+	expected := `## 2.0.0 (2026-10-19)
+
+### ⚠ BREAKING CHANGES
+
+* **api:** list endpoints return pages (ccccccc)
+
+### Features
+
+* **api:** add pagination (ccccccc)
+
+### Bug Fixes
+
+* **parser:** handle empty lines (bbbbbbb)
+`
+	date := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	notes := RenderNotes(Version{Major: 2}, date, commits)
+	if notes != expected {
+		t.Fatalf("Expected: %s, Actual: %s", expected, notes)
+	}
+}
+
+func TestPrependNotes(t *testing.T) {
+	notes := "## 1.1.0 (2026-10-19)\n"
+	cases := []struct {
+		changelog string
+		expected  string
+	}{
+		{"", "# Changelog\n\n## 1.1.0 (2026-10-19)\n"},
+		{"# Changelog\n\n## 1.0.0 (2026-01-01)\n", "# Changelog\n\n## 1.1.0 (2026-10-19)\n\n## 1.0.0 (2026-01-01)\n"},
+		{"## 1.0.0 (2026-01-01)\n", "# Changelog\n\n## 1.1.0 (2026-10-19)\n\n## 1.0.0 (2026-01-01)\n"},
+	}
+
+	for idx, tc := range cases {
+		if actual := PrependNotes(tc.changelog, notes); actual != tc.expected {
+			t.Fatalf("Test case %d failed. Got %q - Exp %q", idx, actual, tc.expected)
+		}
+	}
+}
+
+func TestNotesEntry(t *testing.T) {
+	cases := []struct {
+		message string
+		title   string
+		line    string
+		ok      bool
+	}{
+		{"feat(api): add pagination", "Features", "* **api:** add pagination", true},
+		{"fix: handle empty lines", "Bug Fixes", "* handle empty lines", true},
+		{"refactor!: drop v1\n\nBREAKING CHANGE: v1 is gone", "⚠ BREAKING CHANGES", "* v1 is gone", true},
+		{"docs: update the readme", "", "", false},
+		{"not conventional", "", "", false},
+	}
+
+	for idx, tc := range cases {
+		title, line, ok := NotesEntry(tc.message)
+		if title != tc.title || line != tc.line || ok != tc.ok {
+			t.Fatalf("Test case %d failed. Got %q %q %t - Exp %q %q %t", idx, title, line, ok, tc.title, tc.line, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package release
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/conventional"
+	"cc-lsp/git"
+	"fmt"
+	"strings"
+)
+
+// Options change how the next version is calculated
+type Options struct {
+	// Channel is the pre-release channel (e.g. rc), empty for a stable release
+	Channel string
+	// ZeroMajor keeps the version at 0.x: while the major version is 0
+	// breaking changes only bump the minor version
+	ZeroMajor bool
+	// Package limits the release to one package of a monorepo, nil releases
+	// the whole repository
+	Package *config.Package
+}
+
+// tagPrefix is put in front of the version in tag names
+func (o Options) tagPrefix() string {
+	if o.Package == nil {
+		return ""
+	}
+	return o.Package.TagPrefix()
+}
+
+// includes reports whether the commit is part of the release, for a package
+// a commit counts if it touched a file of the package or uses one of its scopes
+func (o Options) includes(commit git.Commit) bool {
+	if o.Package == nil {
+		return true
+	}
+	for _, file := range commit.Files {
+		if o.Package.HasFile(file) {
+			return true
+		}
+	}
+	parsed := conventional.Parse(commit.Message)
+	return parsed.Conventional && o.Package.HasScope(parsed.Scope)
+}
+
+// Tag is a git tag that holds a semantic version
+type Tag struct {
+	Name    string
+	Version Version
+}
+
+// Plan is the outcome of the version calculation
+type Plan struct {
+	// Latest is the latest stable release, nil if there is none yet
+	Latest *Tag
+	// Commits are the commit messages since the latest stable release
+	Commits []git.Commit
+	Bump    Bump
+	Next    Version
+	// TagName is the name of the tag for the next version, it keeps the
+	// prefix of the latest tag
+	TagName string
+}
+
+// LatestTag returns the tag with the highest version that starts with the
+// prefix, pre-releases are skipped unless pre is set
+func LatestTag(tags []string, prefix string, pre bool) *Tag {
+	var latest *Tag
+	for _, name := range tags {
+		version, err := parseTag(name, prefix)
+		if err != nil || (version.Pre != "" && !pre) {
+			continue
+		}
+		if latest == nil || version.Compare(latest.Version) > 0 {
+			latest = &Tag{Name: name, Version: version}
+		}
+	}
+	return latest
+}
+
+// parseTag parses the version of a tag with the given prefix, tags of
+// packages do not parse without their prefix
+func parseTag(name, prefix string) (Version, error) {
+	version, found := strings.CutPrefix(name, prefix)
+	if !found {
+		return Version{}, fmt.Errorf("tag %s does not start with %s", name, prefix)
+	}
+	return ParseVersion(version)
+}
+
+// LatestRelease returns the latest stable release reachable from HEAD, nil if
+// there is none
+func LatestRelease(repo git.Repo, options Options) (*Tag, error) {
+	merged, err := repo.Tags(true)
+	if err != nil {
+		return nil, err
+	}
+	return LatestTag(merged, options.tagPrefix(), false), nil
+}
+
+// versionPrefix returns the v in front of the version if the latest tag has
+// one or there is no tag yet
+func versionPrefix(latest *Tag, prefix string) string {
+	if latest == nil || strings.HasPrefix(latest.Name, prefix+"v") {
+		return "v"
+	}
+	return ""
+}
+
+// NextVersion finds the latest stable release reachable from HEAD and
+// calculates the next version from the commits since then
+func NextVersion(repo git.Repo, options Options) (Plan, error) {
+	return nextVersion(repo, options, nil)
+}
+
+// PreviewVersion is NextVersion with a commit that is not made yet, e.g. the
+// message that is being written, on top of HEAD
+func PreviewVersion(repo git.Repo, options Options, pending git.Commit) (Plan, error) {
+	return nextVersion(repo, options, &pending)
+}
+
+func nextVersion(repo git.Repo, options Options, pending *git.Commit) (Plan, error) {
+	latest, err := LatestRelease(repo, options)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	since := ""
+	if latest != nil {
+		since = latest.Name
+	}
+	log, err := repo.Log(since)
+	if err != nil {
+		return Plan{}, err
+	}
+	if pending != nil {
+		// the log is newest first
+		log = append([]git.Commit{*pending}, log...)
+	}
+
+	commits := []git.Commit{}
+	messages := []string{}
+	for _, commit := range log {
+		if options.includes(commit) {
+			commits = append(commits, commit)
+			messages = append(messages, commit.Message)
+		}
+	}
+
+	// pre-release numbers must be unique in the whole repository, not only
+	// on the current branch
+	all, err := repo.Tags(false)
+	if err != nil {
+		return Plan{}, err
+	}
+	return plan(latest, commits, BumpForMessages(messages), all, options), nil
+}
+
+func plan(latest *Tag, commits []git.Commit, bump Bump, tags []string, options Options) Plan {
+	current := Version{}
+	if latest != nil {
+		current = latest.Version
+	}
+	if options.ZeroMajor && current.Major == 0 && bump == MajorBump {
+		bump = MinorBump
+	}
+
+	next := bump.Apply(current)
+	if bump != NoBump && options.Channel != "" {
+		number := 0
+		for _, name := range tags {
+			version, err := parseTag(name, options.tagPrefix())
+			if err != nil || version.Stable() != next {
+				continue
+			}
+			if n, ok := version.preRelease(options.Channel); ok {
+				number = max(number, n)
+			}
+		}
+		next.Pre = fmt.Sprintf("%s.%d", options.Channel, number+1)
+	}
+
+	return Plan{
+		Latest:  latest,
+		Commits: commits,
+		Bump:    bump,
+		Next:    next,
+		TagName: options.tagPrefix() + versionPrefix(latest, options.tagPrefix()) + next.String(),
+	}
+}
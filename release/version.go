@@ -0,0 +1,111 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var versionRegexp = regexp.MustCompile(`^(v?)(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// Version is a semantic version like 1.4.2 or 1.5.0-rc.1
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+	// Pre is the pre-release part without the leading dash (e.g. rc.1)
+	Pre string
+}
+
+// ParseVersion parses a semantic version with an optional `v` prefix, build
+// metadata is accepted but dropped
+func ParseVersion(text string) (Version, error) {
+	match := versionRegexp.FindStringSubmatch(text)
+	if match == nil {
+		return Version{}, fmt.Errorf("not a semantic version: %s", text)
+	}
+	major, _ := strconv.Atoi(match[2])
+	minor, _ := strconv.Atoi(match[3])
+	patch, _ := strconv.Atoi(match[4])
+	return Version{Major: major, Minor: minor, Patch: patch, Pre: match[5]}, nil
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	return s
+}
+
+// Stable returns the version without its pre-release part
+func (v Version) Stable() Version {
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+}
+
+// Compare returns -1, 0 or 1 following the semver precedence rules
+func (v Version) Compare(other Version) int {
+	for _, pair := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if pair[0] != pair[1] {
+			return compareInt(pair[0], pair[1])
+		}
+	}
+	// a version without pre-release has a higher precedence
+	switch {
+	case v.Pre == other.Pre:
+		return 0
+	case v.Pre == "":
+		return 1
+	case other.Pre == "":
+		return -1
+	}
+
+	left := strings.Split(v.Pre, ".")
+	right := strings.Split(other.Pre, ".")
+	for i := 0; i < len(left) && i < len(right); i++ {
+		if c := compareIdentifier(left[i], right[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(left), len(right))
+}
+
+func compareIdentifier(a, b string) int {
+	x, errA := strconv.Atoi(a)
+	y, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return compareInt(x, y)
+	// numeric identifiers have a lower precedence than alphanumeric ones
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// preRelease returns the number of a pre-release like rc.3 for the given
+// channel, ok is false if the version is not a pre-release of that channel
+func (v Version) preRelease(channel string) (int, bool) {
+	number, found := strings.CutPrefix(v.Pre, channel+".")
+	if !found {
+		return 0, false
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,162 @@
+package release
+
+import (
+	"cc-lsp/config"
+	"cc-lsp/git"
+	"testing"
+)
+
+func TestVersionCompare(t *testing.T) {
+	// sorted by precedence, taken from the semver specification
+	versions := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "v2.0.0"}
+	for i := 1; i < len(versions); i++ {
+		lower, err := ParseVersion(versions[i-1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		higher, err := ParseVersion(versions[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lower.Compare(higher) != -1 || higher.Compare(lower) != 1 {
+			t.Fatalf("%s should be lower than %s", lower, higher)
+		}
+	}
+
+	for _, invalid := range []string{"1.0", "release-1", "01.0.0", "v1.0.0-"} {
+		if _, err := ParseVersion(invalid); err == nil {
+			t.Fatalf("%s should not be a version", invalid)
+		}
+	}
+}
+
+func TestBumpForMessages(t *testing.T) {
+	cases := []struct {
+		messages []string
+		expected Bump
+	}{
+		{[]string{"docs: readme", "chore stuff"}, NoBump},
+		{[]string{"docs: readme", "fix: a bug"}, PatchBump},
+		{[]string{"perf: faster", "feat(api): pagination", "fix: a bug"}, MinorBump},
+		{[]string{"fix!: drop the endpoint", "feat: pagination"}, MajorBump},
+		{[]string{"refactor: x\n\nBREAKING CHANGE: the config moved"}, MajorBump},
+	}
+
+	for idx, tc := range cases {
+		if bump := BumpForMessages(tc.messages); bump != tc.expected {
+			t.Fatalf("Test case %d failed. Got %s - Exp %s", idx, bump, tc.expected)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	tags := []string{"v0.9.0", "v1.4.2", "v1.5.0-rc.1", "v1.5.0-rc.2", "v2.0.0-beta.1", "not-a-version"}
+	latest := LatestTag(tags, "", false)
+	if latest == nil || latest.Name != "v1.4.2" {
+		t.Fatalf("latest stable tag should be v1.4.2 is %v", latest)
+	}
+	zero := &Tag{Name: "0.3.1", Version: Version{Minor: 3, Patch: 1}}
+
+	cases := []struct {
+		latest   *Tag
+		bump     Bump
+		options  Options
+		expected string
+	}{
+		{latest, NoBump, Options{}, "v1.4.2"},
+		{latest, PatchBump, Options{}, "v1.4.3"},
+		{latest, MinorBump, Options{}, "v1.5.0"},
+		{latest, MajorBump, Options{}, "v2.0.0"},
+		{latest, MinorBump, Options{Channel: "rc"}, "v1.5.0-rc.3"},
+		{latest, MajorBump, Options{Channel: "rc"}, "v2.0.0-rc.1"},
+		{latest, MajorBump, Options{Channel: "beta"}, "v2.0.0-beta.2"},
+		{latest, MajorBump, Options{ZeroMajor: true}, "v2.0.0"},
+		{zero, MajorBump, Options{}, "1.0.0"},
+		{zero, MajorBump, Options{ZeroMajor: true}, "0.4.0"},
+		{zero, MinorBump, Options{ZeroMajor: true}, "0.4.0"},
+		{nil, PatchBump, Options{}, "v0.0.1"},
+		{nil, MinorBump, Options{Channel: "rc"}, "v0.1.0-rc.1"},
+	}
+
+	for idx, tc := range cases {
+		plan := plan(tc.latest, nil, tc.bump, tags, tc.options)
+		if plan.TagName != tc.expected {
+			t.Fatalf("Test case %d failed. Got %s - Exp %s", idx, plan.TagName, tc.expected)
+		}
+	}
+}
+
+func TestPackageTags(t *testing.T) {
+	tags := []string{"v2.0.0", "billing/v1.2.3", "billing/v1.3.0-rc.1", "billing/1.1.0", "billing-v9.0.0", "auth/v3.0.0"}
+	billing := Options{Package: &config.Package{Name: "billing"}}
+
+	cases := []struct {
+		prefix   string
+		expected string
+	}{
+		{"", "v2.0.0"},
+		{"billing/", "billing/v1.2.3"},
+		{"auth/", "auth/v3.0.0"},
+		{"web/", ""},
+	}
+
+	for idx, tc := range cases {
+		latest := LatestTag(tags, tc.prefix, false)
+		actual := ""
+		if latest != nil {
+			actual = latest.Name
+		}
+		if actual != tc.expected {
+			t.Fatalf("Test case %d failed. Got %s - Exp %s", idx, actual, tc.expected)
+		}
+	}
+
+	latest := LatestTag(tags, billing.tagPrefix(), false)
+	plans := []struct {
+		latest   *Tag
+		bump     Bump
+		channel  string
+		expected string
+	}{
+		{latest, PatchBump, "", "billing/v1.2.4"},
+		{latest, MinorBump, "rc", "billing/v1.3.0-rc.2"},
+		{latest, MajorBump, "rc", "billing/v2.0.0-rc.1"},
+		{&Tag{Name: "billing/1.1.0", Version: Version{Major: 1, Minor: 1}}, MinorBump, "", "billing/1.2.0"},
+		{nil, MinorBump, "", "billing/v0.1.0"},
+	}
+
+	for idx, tc := range plans {
+		options := billing
+		options.Channel = tc.channel
+		if plan := plan(tc.latest, nil, tc.bump, tags, options); plan.TagName != tc.expected {
+			t.Fatalf("Test case %d failed. Got %s - Exp %s", idx, plan.TagName, tc.expected)
+		}
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	billing := Options{Package: &config.Package{Name: "billing", Path: "services/billing"}}
+	auth := Options{Package: &config.Package{Name: "auth", Scopes: []string{"login", "session"}}}
+
+	cases := []struct {
+		options  Options
+		commit   git.Commit
+		expected bool
+	}{
+		{Options{}, git.Commit{Message: "fix: anything"}, true},
+		{billing, git.Commit{Message: "fix: rounding", Files: []string{"services/billing/invoice.go"}}, true},
+		{billing, git.Commit{Message: "fix(billing): rounding"}, true},
+		{billing, git.Commit{Message: "fix(api): rounding", Files: []string{"README.md", "services/billing/invoice.go"}}, true},
+		{billing, git.Commit{Message: "fix: rounding", Files: []string{"services/billing-v2/invoice.go"}}, false},
+		{billing, git.Commit{Message: "billing: not conventional"}, false},
+		{auth, git.Commit{Message: "feat(session): expire"}, true},
+		{auth, git.Commit{Message: "feat(auth): expire"}, false},
+		{auth, git.Commit{Message: "feat: expire", Files: []string{"auth/session.go"}}, false},
+	}
+
+	for idx, tc := range cases {
+		if actual := tc.options.includes(tc.commit); actual != tc.expected {
+			t.Fatalf("Test case %d failed. Got %t - Exp %t", idx, actual, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package scopes
+
+import (
+	"cc-lsp/conventional"
+	"cc-lsp/git"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Stat is the usage of a scope in the history of the repository
+type Stat struct {
+	Name  string    `json:"name"`
+	Count int       `json:"count"`
+	Last  time.Time `json:"last"`
+	// Author is the author of the latest commit with the scope
+	Author string `json:"author"`
+	// Recent are the subjects of the latest commits with the scope, newest first
+	Recent []string `json:"recent"`
+}
+
+// recentSubjects is the number of subjects kept per scope
+const recentSubjects = 5
+
+// historyVersion changes when the cache format changes, older caches are rebuilt
+const historyVersion = 3
+
+// History is an index of the scopes used in the git log, it is cached in the
+// git directory and only the new commits are indexed on an update
+type History struct {
+	Version int `json:"version"`
+	// Head is the last indexed commit
+	Head   string           `json:"head"`
+	Scopes map[string]*Stat `json:"scopes"`
+}
+
+// cacheFile is the location of the index inside the git directory
+const cacheFile = "cc-lsp/scopes.json"
+
+func newHistory() *History {
+	return &History{Version: historyVersion, Scopes: map[string]*Stat{}}
+}
+
+// LoadHistory reads the cached index of the repository and brings it up to
+// date with HEAD, the cache is rebuilt if the history was rewritten
+func LoadHistory(repo git.Repo) (*History, error) {
+	path, err := cachePath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	history := newHistory()
+	if content, err := os.ReadFile(path); err == nil {
+		// a broken cache is rebuilt
+		if json.Unmarshal(content, history) != nil || history.Scopes == nil || history.Version != historyVersion {
+			history = newHistory()
+		}
+	}
+
+	return history, history.Refresh(repo)
+}
+
+func cachePath(repo git.Repo) (string, error) {
+	gitDir, err := repo.GitDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, cacheFile), nil
+}
+
+// Refresh brings the index up to date with HEAD, the cache is written if
+// commits were indexed
+func (h *History) Refresh(repo git.Repo) error {
+	changed, err := h.Update(repo)
+	if err != nil || !changed {
+		return err
+	}
+	path, err := cachePath(repo)
+	if err != nil {
+		return err
+	}
+	return h.save(path)
+}
+
+// Update indexes the commits since the last indexed commit and reports
+// whether anything changed
+func (h *History) Update(repo git.Repo) (bool, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return false, err
+	}
+	if head == h.Head {
+		return false, nil
+	}
+	if h.Head != "" && !repo.IsAncestor(h.Head) {
+		*h = *newHistory()
+	}
+
+	commits, err := repo.Subjects(h.Head)
+	if err != nil {
+		return false, err
+	}
+	// the log is newest first, the oldest commits are added first so the
+	// newest subjects end up in front
+	for idx := len(commits) - 1; idx >= 0; idx-- {
+		h.add(commits[idx])
+	}
+	h.Head = head
+	return true, nil
+}
+
+func (h *History) add(commit git.Commit) {
+	parsed := conventional.Parse(commit.Message)
+	if !parsed.Conventional || parsed.Scope == "" {
+		return
+	}
+	stat, ok := h.Scopes[parsed.Scope]
+	if !ok {
+		stat = &Stat{Name: parsed.Scope}
+		h.Scopes[parsed.Scope] = stat
+	}
+	stat.Count++
+	if !commit.Date.Before(stat.Last) {
+		stat.Last = commit.Date
+		stat.Author = commit.Author
+	}
+	stat.Recent = append([]string{commit.Message}, stat.Recent...)
+	if len(stat.Recent) > recentSubjects {
+		stat.Recent = stat.Recent[:recentSubjects]
+	}
+}
+
+func (h *History) save(path string) error {
+	content, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	// write to a temporary file first so a concurrent reader never sees half an index
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0666); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// score weighs the number of uses with their age, the uses of a scope last
+// used 30 days ago count half as much as the uses of a scope used today
+func (s Stat) score(now time.Time) float64 {
+	days := now.Sub(s.Last).Hours() / 24
+	return float64(s.Count) / (1 + max(days, 0)/30)
+}
+
+// Ranked returns the scopes ordered by frequency and recency
+func (h *History) Ranked(now time.Time) []Stat {
+	stats := make([]Stat, 0, len(h.Scopes))
+	for _, stat := range h.Scopes {
+		stats = append(stats, *stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i].score(now), stats[j].score(now)
+		if a != b {
+			return a > b
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// Describe summarizes the usage, e.g. "used 37 times, last 2 days ago"
+func (s Stat) Describe(now time.Time) string {
+	times := "times"
+	if s.Count == 1 {
+		times = "time"
+	}
+	return fmt.Sprintf("used %d %s, last %s", s.Count, times, ago(now.Sub(s.Last)))
+}
+
+func ago(d time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case d < day:
+		return "today"
+	case d < 2*day:
+		return "yesterday"
+	case d < 60*day:
+		return fmt.Sprintf("%d days ago", d/day)
+	case d < 730*day:
+		return fmt.Sprintf("%d months ago", d/(30*day))
+	}
+	return fmt.Sprintf("%d years ago", d/(365*day))
+}
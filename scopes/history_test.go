@@ -0,0 +1,110 @@
+package scopes
+
+import (
+	"cc-lsp/git"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func gitCommit(t *testing.T, dir, message string, date time.Time) {
+	cmd := exec.Command("git", "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--allow-empty", "--message", message)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date.Format(time.RFC3339))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %s", out)
+	}
+}
+
+func TestLoadHistory(t *testing.T) {
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "--quiet", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %s", out)
+	}
+	repo := git.Repo{Dir: dir}
+
+	now := time.Now()
+	old := now.Add(-300 * 24 * time.Hour)
+	for i := 0; i < 5; i++ {
+		gitCommit(t, dir, "fix(parser): old fix", old)
+	}
+	gitCommit(t, dir, "feat(api): add pagination", now)
+	gitCommit(t, dir, "fix(api): off by one", now)
+	gitCommit(t, dir, "docs: no scope", now)
+	gitCommit(t, dir, "not conventional (at all)", now)
+
+	history, err := LoadHistory(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ranked := history.Ranked(now)
+	if len(ranked) != 2 || ranked[0].Name != "api" || ranked[0].Count != 2 || ranked[1].Count != 5 {
+		t.Fatalf("api is used less often but more recently and should be ranked first, got %+v", ranked)
+	}
+	if recent := history.Scopes["api"].Recent; len(recent) != 2 || recent[0] != "fix(api): off by one" {
+		t.Fatalf("the recent subjects should be newest first, got %v", recent)
+	}
+	if author := history.Scopes["api"].Author; author != "test" {
+		t.Fatalf("the author of the latest commit should be kept, got %q", author)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git", cacheFile)); err != nil {
+		t.Fatalf("the index should be cached: %s", err)
+	}
+
+	// only the new commit is indexed on top of the cache
+	gitCommit(t, dir, "feat(cli): add the release command", now)
+	history, err = LoadHistory(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Scopes) != 3 || history.Scopes["api"].Count != 2 || history.Scopes["cli"].Count != 1 {
+		t.Fatalf("the new commit should be added to the cached index, got %+v", history.Scopes)
+	}
+
+	// a rewritten history rebuilds the index
+	cmd := exec.Command("git", "reset", "--quiet", "--hard", "HEAD~4")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git reset: %s", out)
+	}
+	gitCommit(t, dir, "feat(web): add a dashboard", now)
+	if err := history.Refresh(repo); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := history.Scopes["cli"]; ok || history.Scopes["api"].Count != 1 || history.Scopes["web"] == nil {
+		t.Fatalf("the index should be rebuilt after a rewrite, got %+v", history.Scopes)
+	}
+
+	// the refreshed index is cached for the next start
+	content, err := os.ReadFile(filepath.Join(dir, ".git", cacheFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached := newHistory()
+	if err := json.Unmarshal(content, cached); err != nil || cached.Head != history.Head || cached.Scopes["web"] == nil {
+		t.Fatalf("the refreshed index should be cached, got %+v %v", cached, err)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		stat     Stat
+		expected string
+	}{
+		{Stat{Count: 37, Last: now.Add(-50 * time.Hour)}, "used 37 times, last 2 days ago"},
+		{Stat{Count: 1, Last: now}, "used 1 time, last today"},
+		{Stat{Count: 3, Last: now.Add(-30 * time.Hour)}, "used 3 times, last yesterday"},
+		{Stat{Count: 3, Last: now.Add(-100 * 24 * time.Hour)}, "used 3 times, last 3 months ago"},
+		{Stat{Count: 3, Last: now.Add(-800 * 24 * time.Hour)}, "used 3 times, last 2 years ago"},
+	}
+
+	for idx, tc := range cases {
+		if actual := tc.stat.Describe(now); actual != tc.expected {
+			t.Fatalf("Test case %d failed. Got %s - Exp %s", idx, actual, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package scopes
+
+import (
+	"bufio"
+	"bytes"
+	"cc-lsp/config"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// layoutProviders discover scopes from the files in the repository
+var layoutProviders = map[string]func(dir string) ([]string, error){
+	config.GoProvider:          goScopes,
+	config.NpmProvider:         npmScopes,
+	config.CargoProvider:       cargoScopes,
+	config.DirectoriesProvider: directoryScopes,
+}
+
+// Layout returns the scopes the enabled providers find in the repository
+// root dir, without duplicates and in the order of config.ScopeProviders
+func Layout(dir string, cfg config.ScopeConfig) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, name := range config.ScopeProviders {
+		provider, ok := layoutProviders[name]
+		if !ok || !cfg.HasProvider(name) {
+			continue
+		}
+		// a broken manifest should not hide the scopes of the other providers
+		found, err := provider(dir)
+		if err != nil {
+			continue
+		}
+		for _, scope := range found {
+			if scope != "" && !seen[scope] {
+				seen[scope] = true
+				result = append(result, scope)
+			}
+		}
+	}
+	return result
+}
+
+// skipDir reports whether the directory is not part of the project layout
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") ||
+		name == "vendor" || name == "testdata" || name == "node_modules" || name == "target"
+}
+
+// goScopes returns the names of the directories that hold Go packages of
+// the module in dir
+func goScopes(dir string) ([]string, error) {
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+		return nil, err
+	}
+
+	found := map[string]bool{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && skipDir(entry.Name()) {
+				return filepath.SkipDir
+			}
+			// nested modules are not part of this module
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); path != dir && err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) == ".go" && filepath.Dir(path) != dir {
+			found[filepath.Base(filepath.Dir(path))] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sortedKeys(found), nil
+}
+
+// npmScopes returns the packages of the npm or pnpm workspaces in dir
+func npmScopes(dir string) ([]string, error) {
+	patterns := []string{}
+	if content, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		var manifest struct {
+			Workspaces json.RawMessage `json:"workspaces"`
+		}
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			return nil, err
+		}
+		// workspaces is either a list of patterns or an object with packages
+		var list []string
+		var object struct {
+			Packages []string `json:"packages"`
+		}
+		if json.Unmarshal(manifest.Workspaces, &list) == nil {
+			patterns = append(patterns, list...)
+		} else if json.Unmarshal(manifest.Workspaces, &object) == nil {
+			patterns = append(patterns, object.Packages...)
+		}
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "pnpm-workspace.yaml")); err == nil {
+		patterns = append(patterns, pnpmPackages(content)...)
+	}
+
+	found := map[string]bool{}
+	for _, member := range expandMembers(dir, patterns, "package.json") {
+		name := filepath.Base(member)
+		var manifest struct {
+			Name string `json:"name"`
+		}
+		content, err := os.ReadFile(filepath.Join(member, "package.json"))
+		if err == nil && json.Unmarshal(content, &manifest) == nil && manifest.Name != "" {
+			// drop the npm scope of names like @acme/billing
+			name = manifest.Name[strings.LastIndex(manifest.Name, "/")+1:]
+		}
+		found[name] = true
+	}
+	return sortedKeys(found), nil
+}
+
+// pnpmPackages reads the package patterns of a pnpm-workspace.yaml, only the
+// block list below the packages key is supported
+func pnpmPackages(content []byte) []string {
+	patterns := []string{}
+	inPackages := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-") {
+			inPackages = strings.HasPrefix(trimmed, "packages:")
+			continue
+		}
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok && inPackages {
+			patterns = append(patterns, strings.Trim(strings.TrimSpace(item), `'"`))
+		}
+	}
+	return patterns
+}
+
+var (
+	cargoMembersRegexp = regexp.MustCompile(`(?s)\[workspace\][^\[]*?members\s*=\s*\[(.*?)\]`)
+	cargoStringRegexp  = regexp.MustCompile(`"([^"]*)"`)
+	cargoNameRegexp    = regexp.MustCompile(`(?m)^\s*name\s*=\s*"([^"]+)"`)
+)
+
+// cargoScopes returns the crates of the cargo workspace in dir
+func cargoScopes(dir string) ([]string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, "Cargo.toml"))
+	if err != nil {
+		return nil, err
+	}
+	match := cargoMembersRegexp.FindSubmatch(content)
+	if match == nil {
+		return nil, nil
+	}
+	patterns := []string{}
+	for _, member := range cargoStringRegexp.FindAllSubmatch(match[1], -1) {
+		patterns = append(patterns, string(member[1]))
+	}
+
+	found := map[string]bool{}
+	for _, member := range expandMembers(dir, patterns, "Cargo.toml") {
+		name := filepath.Base(member)
+		manifest, err := os.ReadFile(filepath.Join(member, "Cargo.toml"))
+		if err == nil {
+			if match := cargoNameRegexp.FindSubmatch(manifest); match != nil {
+				name = string(match[1])
+			}
+		}
+		found[name] = true
+	}
+	return sortedKeys(found), nil
+}
+
+// directoryScopes returns the top level directories of dir
+func directoryScopes(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	found := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() && !skipDir(entry.Name()) {
+			found = append(found, entry.Name())
+		}
+	}
+	return found, nil
+}
+
+// expandMembers expands the workspace patterns relative to dir and returns
+// the directories that contain the manifest
+func expandMembers(dir string, patterns []string, manifest string) []string {
+	members := []string{}
+	for _, pattern := range patterns {
+		// negated patterns exclude members, they are rare enough to be ignored
+		if strings.HasPrefix(pattern, "!") {
+			continue
+		}
+		pattern = strings.TrimSuffix(strings.ReplaceAll(pattern, "**", "*"), "/")
+		matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(pattern)))
+		if err != nil {
+			continue
+		}
+		for _, match := range matches {
+			if _, err := os.Stat(filepath.Join(match, manifest)); err == nil {
+				members = append(members, match)
+			}
+		}
+	}
+	return members
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
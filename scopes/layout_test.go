@@ -0,0 +1,63 @@
+package scopes
+
+import (
+	"cc-lsp/config"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLayoutProviders(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":                         "module example.com/shop\n",
+		"main.go":                        "package main\n",
+		"internal/billing/billing.go":    "package billing\n",
+		"internal/billing/testdata/x.go": "package x\n",
+		"tools/go.mod":                   "module example.com/tools\n",
+		"tools/lint/lint.go":             "package lint\n",
+		"package.json":                   `{"workspaces": ["web/*"]}`,
+		"web/dashboard/package.json":     `{"name": "@shop/dashboard"}`,
+		"web/shared/package.json":        `{}`,
+		"web/notes.md":                   "not a package",
+		"pnpm-workspace.yaml":            "packages:\n  - 'apps/*'\n  # a comment\n  - \"!apps/legacy\"\ncatalog:\n  - nope\n",
+		"apps/admin/package.json":        `{"name": "admin"}`,
+		"Cargo.toml":                     "[workspace]\nmembers = [\n  \"crates/*\",\n]\n",
+		"crates/parser/Cargo.toml":       "[package]\nname = \"shop-parser\"\n",
+		".github/workflows/ci.yml":       "",
+	})
+
+	cases := []struct {
+		provider string
+		expected []string
+	}{
+		{config.GoProvider, []string{"billing"}},
+		{config.NpmProvider, []string{"admin", "dashboard", "shared"}},
+		{config.CargoProvider, []string{"shop-parser"}},
+		{config.DirectoriesProvider, []string{"apps", "crates", "internal", "tools", "web"}},
+	}
+	for _, tc := range cases {
+		actual := Layout(dir, config.ScopeConfig{Providers: []string{tc.provider}})
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Fatalf("%s: Got %v - Exp %v", tc.provider, actual, tc.expected)
+		}
+	}
+
+	all := Layout(dir, config.ScopeConfig{})
+	if len(all) != 10 {
+		t.Fatalf("all providers should find 10 scopes without duplicates, got %v", all)
+	}
+}